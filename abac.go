@@ -0,0 +1,717 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// ABAC - Attribute-Based Access Control
+// ============================================================================
+
+// ErrResourceNotFound is returned by a ResourceLoader when the requested resource does not exist.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// PolicyInput is the data a policy is evaluated against.
+type PolicyInput struct {
+	Subject  Subject
+	Resource any    // Loaded by the ResourceLoader, nil without one
+	Action   string // Defaults to MethodAction of the request method
+	Env      Environment
+}
+
+// Subject describes the authenticated caller.
+type Subject struct {
+	UserID string         // From GetUserID
+	Roles  []string       // From GetUserRoles
+	Claims map[string]any // From GetClaims, nil without a token
+}
+
+// Environment describes the circumstances of the request.
+type Environment struct {
+	Time     time.Time
+	ClientIP string
+	Method   string
+	Path     string
+}
+
+// Policy decides whether the input is allowed. An error is treated as an internal failure.
+type Policy func(input *PolicyInput) (bool, error)
+
+// ResourceLoader loads the resource targeted by the request, e.g. by c.Param("id").
+// Return an error wrapping ErrResourceNotFound to answer 404.
+type ResourceLoader func(c *gin.Context) (any, error)
+
+// PolicyConfig policy authorization configuration
+type PolicyConfig struct {
+	Loader ResourceLoader              // Optional resource loader
+	Action func(c *gin.Context) string // Action derivation, default MethodAction of the request method
+	Now    func() time.Time            // Clock for Environment.Time, default time.Now
+}
+
+// WithResourceLoader sets the loader for the resource the policy is evaluated against
+func WithResourceLoader(loader ResourceLoader) Option[PolicyConfig] {
+	return func(c *PolicyConfig) {
+		c.Loader = loader
+	}
+}
+
+// WithPolicyAction sets a fixed action instead of deriving it from the HTTP method
+func WithPolicyAction(action string) Option[PolicyConfig] {
+	return func(c *PolicyConfig) {
+		c.Action = func(*gin.Context) string { return action }
+	}
+}
+
+// Authorize evaluates the policy over subject, resource and environment.
+// The loaded resource is available to handlers through GetResource.
+// Denials answer 401 for anonymous callers and 403 otherwise; a missing resource answers
+// 404, or 401 to anonymous callers.
+func Authorize(policy Policy, options ...Option[PolicyConfig]) Middleware {
+	config := &PolicyConfig{
+		Action: func(c *gin.Context) string { return MethodAction(c.Request.Method) },
+		Now:    time.Now,
+	}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			input := &PolicyInput{
+				Action: config.Action(c),
+				Env: Environment{
+					Time:     config.Now(),
+					ClientIP: c.ClientIP(),
+					Method:   c.Request.Method,
+					Path:     c.Request.URL.Path,
+				},
+			}
+			input.Subject.UserID, _ = GetUserID(c)
+			input.Subject.Roles, _ = GetUserRoles(c)
+			input.Subject.Claims, _ = GetClaims(c)
+
+			if config.Loader != nil {
+				resource, err := config.Loader(c)
+				if err != nil {
+					if errors.Is(err, ErrResourceNotFound) {
+						auditDecision(c, "", input.Action, AuditDeny, "resource not found")
+						// Anonymous callers get the deny response, so they cannot probe which resources exist
+						if input.Subject.UserID == "" {
+							abortAuthFailure(c, 401, gin.H{"error": "user not authenticated"})
+							return
+						}
+						c.AbortWithStatusJSON(404, gin.H{"error": "resource not found"})
+						return
+					}
+					auditDecision(c, "", input.Action, AuditError, err.Error())
+					c.Error(err)
+					c.AbortWithStatusJSON(500, gin.H{"error": "resource load failed"})
+					return
+				}
+				input.Resource = resource
+				SetResource(c, resource)
+			}
+
+			allowed, err := policy(input)
+			if err != nil {
+				auditDecision(c, "", input.Action, AuditError, err.Error())
+				c.Error(err)
+				c.AbortWithStatusJSON(500, gin.H{"error": "policy evaluation failed"})
+				return
+			}
+
+			if !allowed {
+				auditDecision(c, "", input.Action, AuditDeny, "policy denied")
+				if input.Subject.UserID == "" {
+					abortAuthFailure(c, 401, gin.H{"error": "user not authenticated"})
+					return
+				}
+				abortAuthFailure(c, 403, gin.H{"error": "access denied"})
+				return
+			}
+
+			auditDecision(c, "", input.Action, AuditAllow, "")
+			next(c)
+		}
+	}
+}
+
+// ============================================================================
+// Policy Combinators and Built-ins
+// ============================================================================
+
+// AllPolicies allows only when every policy allows
+func AllPolicies(policies ...Policy) Policy {
+	return func(input *PolicyInput) (bool, error) {
+		for _, policy := range policies {
+			allowed, err := policy(input)
+			if err != nil || !allowed {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// AnyPolicy allows when at least one policy allows
+func AnyPolicy(policies ...Policy) Policy {
+	return func(input *PolicyInput) (bool, error) {
+		for _, policy := range policies {
+			allowed, err := policy(input)
+			if err != nil {
+				return false, err
+			}
+			if allowed {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// IsOwner allows when the named resource attribute equals the subject's user ID
+func IsOwner(attribute string) Policy {
+	return func(input *PolicyInput) (bool, error) {
+		if input.Subject.UserID == "" {
+			return false, nil
+		}
+		owner, ok := lookupAttribute(input.Resource, attribute)
+		if !ok {
+			return false, nil
+		}
+		return fmt.Sprint(owner) == input.Subject.UserID, nil
+	}
+}
+
+// ============================================================================
+// Policy Expressions
+// ============================================================================
+
+// ParsePolicy compiles a policy expression, for example:
+//
+//	subject.id == resource.owner_id || "admin" in subject.roles
+//	subject.claims.department == resource.department && env.hour >= 9 && env.hour < 18
+//	env.ip in "10.0.0.0/8"
+//
+// Attributes are subject.{id,roles,claims.*}, resource.* (map keys, or struct fields by
+// json tag or name), action and env.{ip,method,path,hour,weekday,time}. Operators are
+// ==, !=, <, <=, >, >=, in (list membership, or CIDR containment for a string), !, && and ||.
+// Literals are strings, numbers, true, false, null and [lists].
+// Missing attributes evaluate to null.
+func ParsePolicy(expr string) (Policy, error) {
+	tokens, err := tokenizePolicy(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &policyParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("policy: unexpected %q", p.tokens[p.pos].text)
+	}
+
+	return func(input *PolicyInput) (bool, error) {
+		value, err := root.eval(input)
+		if err != nil {
+			return false, err
+		}
+		return truthy(value)
+	}, nil
+}
+
+// MustParsePolicy is like ParsePolicy but panics on invalid expressions
+func MustParsePolicy(expr string) Policy {
+	policy, err := ParsePolicy(expr)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+// policyToken kinds
+const (
+	policyIdent = iota
+	policyString
+	policyNumber
+	policyOperator
+)
+
+type policyToken struct {
+	kind int
+	text string
+}
+
+// tokenizePolicy splits an expression into tokens
+func tokenizePolicy(expr string) ([]policyToken, error) {
+	var tokens []policyToken
+	for i := 0; i < len(expr); {
+		ch := expr[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '"' || ch == '\'':
+			end := strings.IndexByte(expr[i+1:], ch)
+			if end < 0 {
+				return nil, errors.New("policy: unterminated string")
+			}
+			tokens = append(tokens, policyToken{policyString, expr[i+1 : i+1+end]})
+			i += end + 2
+		case ch >= '0' && ch <= '9' || ch == '-' && i+1 < len(expr) && expr[i+1] >= '0' && expr[i+1] <= '9':
+			j := i + 1
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, policyToken{policyNumber, expr[i:j]})
+			i = j
+		case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+			j := i + 1
+			for j < len(expr) && (expr[j] == '_' || expr[j] == '.' || expr[j] >= 'a' && expr[j] <= 'z' ||
+				expr[j] >= 'A' && expr[j] <= 'Z' || expr[j] >= '0' && expr[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, policyToken{policyIdent, expr[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("policy: unexpected character %q", ch)
+			}
+			tokens = append(tokens, policyToken{policyOperator, op})
+			i += len(op)
+		}
+	}
+	return tokens, nil
+}
+
+// policyNode is a compiled expression node
+type policyNode interface {
+	eval(input *PolicyInput) (any, error)
+}
+
+type literalNode struct{ value any }
+
+type attributeNode struct{ path []string }
+
+type listNode struct{ items []policyNode }
+
+type notNode struct{ operand policyNode }
+
+type logicalNode struct {
+	and         bool
+	left, right policyNode
+}
+
+type compareNode struct {
+	op          string
+	left, right policyNode
+}
+
+// policyParser is a recursive descent parser for policy expressions
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+func (p *policyParser) peek(text string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	tok := p.tokens[p.pos]
+	return tok.text == text && (tok.kind == policyOperator || tok.kind == policyIdent)
+}
+
+func (p *policyParser) parseOr() (policyNode, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var right policyNode
+		right, err = p.parseAnd()
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *policyParser) parseAnd() (policyNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var right policyNode
+		right, err = p.parseUnary()
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *policyParser) parseUnary() (policyNode, error) {
+	if p.peek("!") {
+		p.pos++
+		operand, err := p.parseUnary()
+		return &notNode{operand: operand}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *policyParser) parseComparison() (policyNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.peek(op) {
+			p.pos++
+			right, err := p.parsePrimary()
+			return &compareNode{op: op, left: left, right: right}, err
+		}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parsePrimary() (policyNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("policy: unexpected end of expression")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case policyString:
+		return &literalNode{tok.text}, nil
+	case policyNumber:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("policy: invalid number %q", tok.text)
+		}
+		return &literalNode{f}, nil
+	case policyIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "null":
+			return &literalNode{nil}, nil
+		}
+		path := strings.Split(tok.text, ".")
+		switch path[0] {
+		case "action":
+			if len(path) > 1 {
+				return nil, fmt.Errorf("policy: unknown attribute %q, action has no fields", tok.text)
+			}
+			return &attributeNode{path}, nil
+		case "subject", "resource", "env":
+			return &attributeNode{path}, nil
+		}
+		return nil, fmt.Errorf("policy: unknown attribute %q", tok.text)
+	}
+
+	switch tok.text {
+	case "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, errors.New("policy: missing )")
+		}
+		p.pos++
+		return node, nil
+	case "[":
+		list := &listNode{}
+		for !p.peek("]") {
+			item, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			list.items = append(list.items, item)
+			if p.peek(",") {
+				p.pos++
+			} else if !p.peek("]") {
+				return nil, errors.New("policy: missing ]")
+			}
+		}
+		p.pos++
+		return list, nil
+	}
+	return nil, fmt.Errorf("policy: unexpected %q", tok.text)
+}
+
+func (n *literalNode) eval(*PolicyInput) (any, error) {
+	return n.value, nil
+}
+
+func (n *listNode) eval(input *PolicyInput) (any, error) {
+	values := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (n *notNode) eval(input *PolicyInput) (any, error) {
+	value, err := n.operand.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truthy(value)
+	return !b, err
+}
+
+func (n *logicalNode) eval(input *PolicyInput) (any, error) {
+	value, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	left, err := truthy(value)
+	if err != nil {
+		return nil, err
+	}
+	if left != n.and {
+		return left, nil
+	}
+	value, err = n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(value)
+}
+
+func (n *compareNode) eval(input *PolicyInput) (any, error) {
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return policyEqual(left, right), nil
+	case "!=":
+		return !policyEqual(left, right), nil
+	case "in":
+		return policyIn(left, right), nil
+	}
+
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compareOrdered(n.op, l, r), nil
+		}
+	}
+	if l, ok := left.(time.Time); ok {
+		if r, ok := right.(time.Time); ok {
+			return compareOrdered(n.op, l.Unix(), r.Unix()), nil
+		}
+	}
+	return false, nil
+}
+
+func (n *attributeNode) eval(input *PolicyInput) (any, error) {
+	var value any
+	var ok bool
+	switch n.path[0] {
+	case "action":
+		return input.Action, nil
+	case "subject":
+		value, ok = map[string]any{
+			"id":     input.Subject.UserID,
+			"roles":  input.Subject.Roles,
+			"claims": input.Subject.Claims,
+		}, true
+	case "resource":
+		value, ok = input.Resource, input.Resource != nil
+	case "env":
+		value, ok = map[string]any{
+			"time":    input.Env.Time,
+			"hour":    input.Env.Time.Hour(),
+			"weekday": input.Env.Time.Weekday().String(),
+			"ip":      input.Env.ClientIP,
+			"method":  input.Env.Method,
+			"path":    input.Env.Path,
+		}, true
+	}
+
+	for _, name := range n.path[1:] {
+		if !ok {
+			return nil, nil
+		}
+		value, ok = lookupAttribute(value, name)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return normalizePolicyValue(value), nil
+}
+
+// lookupAttribute reads a map key or a struct field (by json tag or case-insensitive name)
+func lookupAttribute(object any, name string) (any, bool) {
+	if object == nil {
+		return nil, false
+	}
+	if m, ok := object.(map[string]any); ok {
+		value, exists := m[name]
+		return value, exists
+	}
+
+	v := reflect.ValueOf(object)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return value.Interface(), true
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if tag == name || tag == "" && strings.EqualFold(field.Name, name) {
+				return v.Field(i).Interface(), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// normalizePolicyValue converts numbers to float64 and slices to []any
+func normalizePolicyValue(value any) any {
+	switch v := value.(type) {
+	case nil, string, bool, float64, time.Time, []any, map[string]any:
+		return v
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = normalizePolicyValue(rv.Index(i).Interface())
+		}
+		return items
+	}
+	return value
+}
+
+// truthy converts an expression result to a decision; null counts as false
+func truthy(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("policy: %v is not a boolean", value)
+}
+
+// policyEqual compares two normalized values
+func policyEqual(left, right any) bool {
+	if l, ok := left.(time.Time); ok {
+		r, ok := right.(time.Time)
+		return ok && l.Equal(r)
+	}
+	switch left.(type) {
+	case []any, map[string]any:
+		return reflect.DeepEqual(left, right)
+	}
+	switch right.(type) {
+	case []any, map[string]any:
+		return false
+	}
+	// Maps, slices and structs holding them would panic on ==
+	if !reflect.ValueOf(left).Comparable() || !reflect.ValueOf(right).Comparable() {
+		return reflect.DeepEqual(left, right)
+	}
+	return left == right
+}
+
+// policyIn checks list membership, or CIDR containment when right is a network string
+func policyIn(left, right any) bool {
+	switch r := right.(type) {
+	case []any:
+		return slices.ContainsFunc(r, func(item any) bool { return policyEqual(left, item) })
+	case string:
+		ip, ok := left.(string)
+		if !ok {
+			return false
+		}
+		_, network, err := net.ParseCIDR(r)
+		if err != nil {
+			return false
+		}
+		parsed := net.ParseIP(ip)
+		return parsed != nil && network.Contains(parsed)
+	}
+	return false
+}
+
+// compareOrdered applies an ordering operator
+func compareOrdered[T int64 | float64 | string](op string, l, r T) bool {
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDocument struct {
+	ID         string `json:"id"`
+	OwnerID    string `json:"owner_id"`
+	Department string
+	Level      int `json:"level"`
+}
+
+func TestParsePolicy(t *testing.T) {
+	input := &PolicyInput{
+		Subject: Subject{
+			UserID: "alice",
+			Roles:  []string{"manager"},
+			Claims: map[string]any{"department": "sales", "level": json.Number("3")},
+		},
+		Resource: &testDocument{ID: "doc1", OwnerID: "bob", Department: "sales", Level: 2},
+		Action:   "read",
+		Env: Environment{
+			Time:     time.Date(2024, 5, 6, 10, 30, 0, 0, time.UTC),
+			ClientIP: "10.1.2.3",
+			Method:   "GET",
+			Path:     "/docs/doc1",
+		},
+	}
+
+	testCases := []struct {
+		expr string
+		want bool
+	}{
+		{`subject.id == resource.owner_id`, false},
+		{`subject.id == resource.owner_id || "manager" in subject.roles`, true},
+		{`subject.claims.department == resource.department`, true},
+		{`subject.claims.level > resource.level`, true},
+		{`subject.claims.level >= 3 && subject.claims.level < 4`, true},
+		{`env.hour >= 9 && env.hour < 18 && env.weekday == "Monday"`, true},
+		{`env.ip in "10.0.0.0/8"`, true},
+		{`env.ip in "192.168.0.0/16"`, false},
+		{`action in ["read", "list"]`, true},
+		{`!(action == "delete")`, true},
+		{`env.method != 'GET'`, false},
+		{`subject.claims.missing == null`, true},
+		{`subject.claims.missing`, false},
+		{`resource.missing == "x"`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			policy, err := ParsePolicy(tc.expr)
+			require.NoError(t, err)
+
+			allowed, err := policy(input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, allowed)
+		})
+	}
+
+	t.Run("should reject invalid expressions", func(t *testing.T) {
+		for _, expr := range []string{
+			`subject.id ==`,
+			`(subject.id == "a"`,
+			`user.id == "a"`,
+			`subject.id == "a`,
+			`subject.id = "a"`,
+			`subject.id == "a" "b"`,
+			`action.name == "read"`,
+		} {
+			_, err := ParsePolicy(expr)
+			assert.Error(t, err, expr)
+		}
+		assert.Panics(t, func() { MustParsePolicy(`(`) })
+	})
+
+	t.Run("should compare uncomparable values without panicking", func(t *testing.T) {
+		input := &PolicyInput{Resource: map[string]any{
+			"a": map[string]int{"x": 1},
+			"b": map[string]int{"x": 1},
+			"c": struct{ Tags []string }{[]string{"t"}},
+			"d": struct{ Tags []string }{[]string{"t"}},
+		}}
+
+		for expr, want := range map[string]bool{
+			`resource.a == resource.b`: true,
+			`resource.c == resource.d`: true,
+			`resource.a != resource.c`: true,
+		} {
+			allowed, err := MustParsePolicy(expr)(input)
+			require.NoError(t, err, expr)
+			assert.Equal(t, want, allowed, expr)
+		}
+	})
+
+	t.Run("should not convert Stringers to strings", func(t *testing.T) {
+		input := &PolicyInput{Resource: map[string]any{"ip": net.ParseIP("10.0.0.1")}}
+
+		allowed, err := MustParsePolicy(`resource.ip == "10.0.0.1"`)(input)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("should fail on non-boolean results", func(t *testing.T) {
+		policy := MustParsePolicy(`subject.id`)
+
+		_, err := policy(input)
+		assert.Error(t, err)
+	})
+}
+
+func TestPolicyCombinators(t *testing.T) {
+	allow := func(*PolicyInput) (bool, error) { return true, nil }
+	deny := func(*PolicyInput) (bool, error) { return false, nil }
+	fail := func(*PolicyInput) (bool, error) { return false, errors.New("boom") }
+	input := &PolicyInput{}
+
+	allowed, err := AllPolicies(allow, allow)(input)
+	assert.True(t, allowed)
+	assert.NoError(t, err)
+
+	allowed, _ = AllPolicies(allow, deny)(input)
+	assert.False(t, allowed)
+
+	allowed, _ = AnyPolicy(deny, allow)(input)
+	assert.True(t, allowed)
+
+	_, err = AnyPolicy(deny, fail)(input)
+	assert.Error(t, err)
+
+	owned := &PolicyInput{Subject: Subject{UserID: "alice"}, Resource: map[string]any{"owner_id": "alice"}}
+	allowed, _ = IsOwner("owner_id")(owned)
+	assert.True(t, allowed)
+
+	allowed, _ = IsOwner("owner_id")(&PolicyInput{Resource: map[string]any{"owner_id": ""}})
+	assert.False(t, allowed)
+}
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	documents := map[string]*testDocument{
+		"doc1": {ID: "doc1", OwnerID: "alice", Department: "sales"},
+		"doc2": {ID: "doc2", OwnerID: "bob", Department: "hr"},
+	}
+	loader := func(c *gin.Context) (any, error) {
+		switch id := c.Param("id"); id {
+		case "broken":
+			return nil, errors.New("database error")
+		default:
+			doc, ok := documents[id]
+			if !ok {
+				return nil, fmt.Errorf("document %s: %w", id, ErrResourceNotFound)
+			}
+			return doc, nil
+		}
+	}
+	policy := AnyPolicy(
+		IsOwner("owner_id"),
+		MustParsePolicy(`"manager" in subject.roles && subject.claims.department == resource.department && action == "read"`),
+	)
+
+	newRouter := func(userID string, roles []string, claims map[string]any) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if userID != "" {
+				SetUserID(c, userID)
+				SetUserRoles(c, roles)
+			}
+			if claims != nil {
+				SetToken(c, &jwt.Token{UserID: userID, Raw: rawTestToken(t, claims)})
+			}
+			c.Next()
+		})
+		handler := NewChain().Use(Authorize(policy, WithResourceLoader(loader))).Build()
+		r.Any("/docs/:id", handler, func(c *gin.Context) {
+			resource, _ := GetResource(c)
+			c.JSON(http.StatusOK, gin.H{"id": resource.(*testDocument).ID})
+		})
+		return r
+	}
+
+	serve := func(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	t.Run("owner may edit own document", func(t *testing.T) {
+		w := serve(newRouter("alice", nil, nil), "PUT", "/docs/doc1")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "doc1")
+	})
+
+	t.Run("non-owner is denied", func(t *testing.T) {
+		w := serve(newRouter("alice", nil, nil), "PUT", "/docs/doc2")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("manager may view documents of own department", func(t *testing.T) {
+		r := newRouter("carol", []string{"manager"}, map[string]any{"department": "hr"})
+
+		assert.Equal(t, http.StatusOK, serve(r, "GET", "/docs/doc2").Code)
+		assert.Equal(t, http.StatusForbidden, serve(r, "DELETE", "/docs/doc2").Code)
+		assert.Equal(t, http.StatusForbidden, serve(r, "GET", "/docs/doc1").Code)
+	})
+
+	t.Run("anonymous caller gets 401", func(t *testing.T) {
+		w := serve(newRouter("", nil, nil), "GET", "/docs/doc1")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("missing resource gets 404", func(t *testing.T) {
+		w := serve(newRouter("alice", nil, nil), "GET", "/docs/nope")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("missing resource gets 401 for anonymous caller", func(t *testing.T) {
+		w := serve(newRouter("", nil, nil), "GET", "/docs/nope")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, serve(newRouter("", nil, nil), "GET", "/docs/doc1").Body.String(), w.Body.String())
+	})
+
+	t.Run("loader failure gets 500", func(t *testing.T) {
+		w := serve(newRouter("alice", nil, nil), "GET", "/docs/broken")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("policy error gets 500", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "alice")
+
+		Authorize(MustParsePolicy(`subject.id`))(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("fixed action and environment", func(t *testing.T) {
+		c, w := TestContext("POST", "/reports", nil)
+		SetUserID(c, "alice")
+
+		Authorize(MustParsePolicy(`action == "export" && env.path == "/reports"`), WithPolicyAction("export"))(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,239 @@
+package ginx
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// API Key Store
+// ============================================================================
+
+// ErrAPIKeyNotFound is returned by a KeyStore when no key matches the given hash.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey describes a stored API key and the identity of its owner.
+// Only the SHA-256 hash of the key is kept; the raw key is never stored.
+type APIKey struct {
+	ID        string    // Stable identifier of the key (safe to log)
+	Hash      string    // Hex-encoded SHA-256 hash of the raw key
+	UserID    string    // Owner of the key, stored via SetUserID
+	Roles     []string  // Owner roles, stored via SetUserRoles
+	ExpiresAt time.Time // Zero value means the key never expires
+}
+
+// KeyStore defines the interface for looking up API keys by their hash.
+// Implementations can be backed by memory, a database or a remote service.
+type KeyStore interface {
+	// Lookup returns the key whose hash equals the given hex-encoded SHA-256 hash.
+	// It must return ErrAPIKeyNotFound when no key matches.
+	Lookup(hash string) (*APIKey, error)
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of a raw API key.
+// Use it to prepare hashes for custom KeyStore implementations.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryKeyStore provides a thread-safe, in-memory implementation of KeyStore.
+type MemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*APIKey // hash -> key
+}
+
+// NewMemoryKeyStore creates an empty in-memory key store.
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{
+		keys: make(map[string]*APIKey),
+	}
+}
+
+// Add hashes the raw key and stores it with the given owner information.
+// The Hash field of key is ignored and replaced with the hash of rawKey.
+func (s *MemoryKeyStore) Add(rawKey string, key APIKey) {
+	key.Hash = HashAPIKey(rawKey)
+	s.AddHashed(key)
+}
+
+// AddHashed stores a key whose Hash field is already set.
+func (s *MemoryKeyStore) AddHashed(key APIKey) {
+	key.Roles = append([]string(nil), key.Roles...)
+	s.mu.Lock()
+	s.keys[key.Hash] = &key
+	s.mu.Unlock()
+}
+
+// Revoke removes the key with the given ID.
+func (s *MemoryKeyStore) Revoke(id string) {
+	s.mu.Lock()
+	for hash, key := range s.keys {
+		if key.ID == id {
+			delete(s.keys, hash)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// Lookup returns the key stored under the given hash.
+func (s *MemoryKeyStore) Lookup(hash string) (*APIKey, error) {
+	s.mu.RLock()
+	key, exists := s.keys[hash]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+	cp := *key
+	cp.Roles = append([]string(nil), key.Roles...)
+	return &cp, nil
+}
+
+// ============================================================================
+// Middleware - API Key Authentication
+// ============================================================================
+
+// APIKeyConfig API key authentication configuration
+type APIKeyConfig struct {
+	Header     string // Header carrying the key, defaults to X-API-Key; empty disables header lookup
+	QueryParam string // Query parameter carrying the key, disabled by default
+}
+
+// defaultAPIKeyConfig returns default API key configuration
+func defaultAPIKeyConfig() *APIKeyConfig {
+	return &APIKeyConfig{
+		Header: "X-API-Key",
+	}
+}
+
+// WithAPIKeyHeader sets the header name to read the key from
+func WithAPIKeyHeader(header string) Option[APIKeyConfig] {
+	return func(c *APIKeyConfig) {
+		c.Header = header
+	}
+}
+
+// WithAPIKeyQuery sets the query parameter to read the key from when the header is absent
+func WithAPIKeyQuery(param string) Option[APIKeyConfig] {
+	return func(c *APIKeyConfig) {
+		c.QueryParam = param
+	}
+}
+
+// APIKeyAuth is API key authentication middleware.
+// It fills SetUserID, SetUserRoles and SetAPIKeyID from the key's owner,
+// so RBAC middlewares and per-user rate limiting work unchanged.
+func APIKeyAuth(store KeyStore, options ...Option[APIKeyConfig]) Middleware {
+	authenticator := APIKeyAuthenticator(store, options...)
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			identity, err := authenticator.Authenticate(c)
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrNoCredentials):
+					abortAuthFailure(c, 401, gin.H{"error": "missing api key"})
+				case errors.Is(err, ErrInvalidCredentials):
+					abortAuthFailure(c, 401, gin.H{"error": "invalid api key"})
+				default:
+					c.Error(err)
+					c.AbortWithStatusJSON(500, gin.H{"error": "api key lookup failed"})
+				}
+				return
+			}
+
+			// Set owner information to context
+			SetIdentity(c, identity)
+
+			next(c)
+		}
+	}
+}
+
+// apiKeyAuthenticator authenticates requests carrying an API key
+type apiKeyAuthenticator struct {
+	store  KeyStore
+	config *APIKeyConfig
+}
+
+// APIKeyAuthenticator creates an Authenticator for API keys, for use with AuthAny/AuthAll.
+func APIKeyAuthenticator(store KeyStore, options ...Option[APIKeyConfig]) Authenticator {
+	config := defaultAPIKeyConfig()
+	for _, option := range options {
+		option(config)
+	}
+	return &apiKeyAuthenticator{store: store, config: config}
+}
+
+// Authenticate looks up the API key and returns the identity of its owner
+func (a *apiKeyAuthenticator) Authenticate(c *gin.Context) (*Identity, error) {
+	rawKey := extractAPIKey(c, a.config)
+	if rawKey == "" {
+		return nil, ErrNoCredentials
+	}
+
+	key, err := lookupAPIKey(a.store, rawKey)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		return nil, err
+	}
+
+	return &Identity{
+		UserID:   key.UserID,
+		Roles:    key.Roles,
+		Scheme:   "ApiKey",
+		APIKeyID: key.ID,
+	}, nil
+}
+
+// Challenge returns an ApiKey challenge naming the expected header
+func (a *apiKeyAuthenticator) Challenge(c *gin.Context) string {
+	if a.config.Header == "" {
+		return ""
+	}
+	return `ApiKey header=` + strconv.Quote(a.config.Header)
+}
+
+// extractAPIKey extracts the API key from the configured header or query parameter.
+func extractAPIKey(c *gin.Context, config *APIKeyConfig) string {
+	if config.Header != "" {
+		if key := strings.TrimSpace(c.GetHeader(config.Header)); key != "" {
+			return key
+		}
+	}
+
+	// Fallback to query parameter
+	if config.QueryParam != "" {
+		return c.Query(config.QueryParam)
+	}
+
+	return ""
+}
+
+// lookupAPIKey hashes the raw key, looks it up and verifies it in constant time.
+// Expired keys are reported as ErrAPIKeyNotFound.
+func lookupAPIKey(store KeyStore, rawKey string) (*APIKey, error) {
+	hash := HashAPIKey(rawKey)
+	key, err := store.Lookup(hash)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) != 1 {
+		return nil, ErrAPIKeyNotFound
+	}
+	if !key.ExpiresAt.IsZero() && time.Now().After(key.ExpiresAt) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
@@ -0,0 +1,203 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingKeyStore always returns the configured error
+type failingKeyStore struct {
+	err error
+}
+
+func (s *failingKeyStore) Lookup(hash string) (*APIKey, error) {
+	return nil, s.err
+}
+
+func TestMemoryKeyStore(t *testing.T) {
+	t.Run("should store only the hash of the key", func(t *testing.T) {
+		store := NewMemoryKeyStore()
+		store.Add("secret-key", APIKey{ID: "key1", UserID: "svc-a"})
+
+		key, err := store.Lookup(HashAPIKey("secret-key"))
+		assert.NoError(t, err)
+		assert.Equal(t, "key1", key.ID)
+		assert.Equal(t, HashAPIKey("secret-key"), key.Hash)
+		assert.NotContains(t, key.Hash, "secret-key")
+	})
+
+	t.Run("should return ErrAPIKeyNotFound for unknown hash", func(t *testing.T) {
+		store := NewMemoryKeyStore()
+
+		key, err := store.Lookup(HashAPIKey("unknown"))
+		assert.Nil(t, key)
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+
+	t.Run("should revoke key by ID", func(t *testing.T) {
+		store := NewMemoryKeyStore()
+		store.Add("secret-key", APIKey{ID: "key1", UserID: "svc-a"})
+		store.Revoke("key1")
+
+		_, err := store.Lookup(HashAPIKey("secret-key"))
+		assert.ErrorIs(t, err, ErrAPIKeyNotFound)
+	})
+
+	t.Run("should not expose internal roles slice", func(t *testing.T) {
+		store := NewMemoryKeyStore()
+		store.Add("secret-key", APIKey{ID: "key1", UserID: "svc-a", Roles: []string{"reader"}})
+
+		key, _ := store.Lookup(HashAPIKey("secret-key"))
+		key.Roles[0] = "admin"
+
+		key, _ = store.Lookup(HashAPIKey("secret-key"))
+		assert.Equal(t, []string{"reader"}, key.Roles)
+	})
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newStore := func() *MemoryKeyStore {
+		store := NewMemoryKeyStore()
+		store.Add("valid-key", APIKey{ID: "key1", UserID: "svc-a", Roles: []string{"reader", "writer"}})
+		return store
+	}
+
+	t.Run("should set user information from key owner", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "valid-key"})
+
+		var userID, keyID string
+		var roles []string
+		handler := APIKeyAuth(newStore())(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			roles, _ = GetUserRoles(c)
+			keyID, _ = GetAPIKeyID(c)
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "svc-a", userID)
+		assert.Equal(t, []string{"reader", "writer"}, roles)
+		assert.Equal(t, "key1", keyID)
+	})
+
+	t.Run("should return 401 when key is missing", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+
+		handler := APIKeyAuth(newStore())(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "missing api key", response["error"])
+	})
+
+	t.Run("should return 401 when key is invalid", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "wrong-key"})
+
+		handler := APIKeyAuth(newStore())(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "invalid api key", response["error"])
+	})
+
+	t.Run("should return 401 when key is expired", func(t *testing.T) {
+		store := NewMemoryKeyStore()
+		store.Add("old-key", APIKey{ID: "key2", UserID: "svc-b", ExpiresAt: time.Now().Add(-time.Minute)})
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "old-key"})
+
+		handler := APIKeyAuth(store)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should use custom header", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization-Key": "valid-key"})
+
+		handler := APIKeyAuth(newStore(), WithAPIKeyHeader("Authorization-Key"))(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should ignore query parameter unless enabled", func(t *testing.T) {
+		c, w := TestContext("GET", "/test?api_key=valid-key", nil)
+
+		handler := APIKeyAuth(newStore())(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should read key from query parameter when enabled", func(t *testing.T) {
+		c, w := TestContext("GET", "/test?api_key=valid-key", nil)
+
+		handler := APIKeyAuth(newStore(), WithAPIKeyQuery("api_key"))(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return 500 when store fails", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "valid-key"})
+
+		handler := APIKeyAuth(&failingKeyStore{err: errors.New("database error")})(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Len(t, c.Errors, 1)
+	})
+
+	t.Run("should work with RequirePermission", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "svc-a", "orders", "read").Return(true, nil)
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "valid-key"})
+
+		handler := NewChain().
+			Use(APIKeyAuth(newStore())).
+			Use(RequirePermission(mockRBAC, "orders", "read")).
+			Build()
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+}
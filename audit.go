@@ -0,0 +1,185 @@
+package ginx
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Audit - Authorization Decision Log
+// ============================================================================
+
+// Audit decisions
+const (
+	AuditAllow = "allow"
+	AuditDeny  = "deny"
+	AuditError = "error" // The decision could not be made, e.g. the RBAC backend failed
+)
+
+// AuditEvent records a single authorization decision.
+type AuditEvent struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	Roles     []string  `json:"roles,omitempty"`
+	Resource  string    `json:"resource,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	Decision  string    `json:"decision"`
+	Reason    string    `json:"reason,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+}
+
+// AuditSink stores audit events. Record is called synchronously on the request path.
+type AuditSink interface {
+	Record(event AuditEvent) error
+}
+
+// AuditConfig audit configuration
+type AuditConfig struct {
+	AllowSampleRate float64 // Fraction of allow decisions recorded, default 1; deny and error are always recorded
+}
+
+// WithAllowSampleRate sets the fraction (0..1) of allow decisions that are recorded
+func WithAllowSampleRate(rate float64) Option[AuditConfig] {
+	return func(c *AuditConfig) {
+		c.AllowSampleRate = rate
+	}
+}
+
+// auditor is stored in the context by the Audit middleware
+type auditor struct {
+	sink   AuditSink
+	config *AuditConfig
+}
+
+// Audit records the authorization decisions of the ginx authorization middlewares
+// (RequirePermission*, RequireRoles, RequireScopes, Authorize, ...) running after it.
+// Sink errors are added to the context via c.Error and never change the response.
+func Audit(sink AuditSink, options ...Option[AuditConfig]) Middleware {
+	config := &AuditConfig{AllowSampleRate: 1}
+	for _, opt := range options {
+		opt(config)
+	}
+	a := &auditor{sink: sink, config: config}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(string(auditorKey), a)
+			next(c)
+		}
+	}
+}
+
+// auditDecision records an authorization decision when an Audit middleware is installed
+func auditDecision(c *gin.Context, resource, action, decision, reason string) {
+	value, exists := c.Get(string(auditorKey))
+	if !exists {
+		return
+	}
+	a, ok := value.(*auditor)
+	if !ok {
+		return
+	}
+	if decision == AuditAllow && a.config.AllowSampleRate < 1 && rand.Float64() >= a.config.AllowSampleRate {
+		return
+	}
+
+	event := AuditEvent{
+		Time:     time.Now(),
+		Resource: resource,
+		Action:   action,
+		Decision: decision,
+		Reason:   reason,
+		ClientIP: c.ClientIP(),
+		Method:   c.Request.Method,
+		Path:     c.Request.URL.Path,
+	}
+	event.RequestID, _ = GetRequestID(c)
+	event.UserID, _ = GetUserID(c)
+	event.Roles, _ = GetUserRoles(c)
+
+	if err := a.sink.Record(event); err != nil {
+		c.Error(err)
+	}
+}
+
+// ============================================================================
+// Built-in Sinks
+// ============================================================================
+
+// loggerAuditSink writes audit events to a structured logger
+type loggerAuditSink struct {
+	log *slog.Logger
+}
+
+// NewLoggerAuditSink creates a sink writing one log record per decision:
+// Info for allow, Warn for deny and Error for error decisions.
+// A simp-lee/logger Logger can be passed through its embedded *slog.Logger.
+func NewLoggerAuditSink(log *slog.Logger) AuditSink {
+	return &loggerAuditSink{log: log}
+}
+
+// Record implements AuditSink
+func (s *loggerAuditSink) Record(event AuditEvent) error {
+	level := slog.LevelInfo
+	switch event.Decision {
+	case AuditDeny:
+		level = slog.LevelWarn
+	case AuditError:
+		level = slog.LevelError
+	}
+
+	s.log.LogAttrs(context.Background(), level, "Authorization decision",
+		slog.Time("time", event.Time),
+		slog.String("request_id", event.RequestID),
+		slog.String("user_id", event.UserID),
+		slog.Any("roles", event.Roles),
+		slog.String("resource", event.Resource),
+		slog.String("action", event.Action),
+		slog.String("decision", event.Decision),
+		slog.String("reason", event.Reason),
+		slog.String("ip", event.ClientIP),
+		slog.String("method", event.Method),
+		slog.String("path", event.Path),
+	)
+	return nil
+}
+
+// FileAuditSink appends audit events to a file as JSON lines.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileAuditSink opens (or creates) a JSONL audit file in append mode
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Record implements AuditSink
+func (s *FileAuditSink) Record(event AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(event)
+}
+
+// Close closes the audit file
+func (s *FileAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,203 @@
+package ginx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAuditSink collects audit events for assertions
+type memoryAuditSink struct {
+	events []AuditEvent
+	err    error
+}
+
+func (s *memoryAuditSink) Record(event AuditEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(sink AuditSink, mw Middleware, setup func(c *gin.Context), options ...Option[AuditConfig]) (*gin.Context, int) {
+		c, w := TestContext("DELETE", "/posts/1", nil)
+		SetRequestID(c, "req-1")
+		if setup != nil {
+			setup(c)
+		}
+		NewChain().Use(Audit(sink, options...)).Use(mw).Build()(c)
+		return c, w.Code
+	}
+	authenticated := func(c *gin.Context) {
+		SetUserID(c, "user123")
+		SetUserRoles(c, []string{"editor"})
+	}
+
+	t.Run("should record allow decisions with request details", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "delete").Return(true, nil)
+		sink := &memoryAuditSink{}
+
+		_, code := run(sink, RequirePermission(mockRBAC, "posts", "delete"), authenticated)
+
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, sink.events, 1)
+		event := sink.events[0]
+		assert.Equal(t, AuditAllow, event.Decision)
+		assert.Equal(t, "user123", event.UserID)
+		assert.Equal(t, []string{"editor"}, event.Roles)
+		assert.Equal(t, "posts", event.Resource)
+		assert.Equal(t, "delete", event.Action)
+		assert.Equal(t, "req-1", event.RequestID)
+		assert.Equal(t, "DELETE", event.Method)
+		assert.Equal(t, "/posts/1", event.Path)
+		assert.NotEmpty(t, event.ClientIP)
+		assert.False(t, event.Time.IsZero())
+	})
+
+	t.Run("should record deny and error decisions with reason", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasRolePermission", "user123", "posts", "delete").Return(false, nil)
+		mockRBAC.On("HasUserPermission", "user123", "posts", "delete").Return(false, errors.New("database error"))
+		sink := &memoryAuditSink{}
+
+		_, code := run(sink, RequireRolePermission(mockRBAC, "posts", "delete"), authenticated)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		_, code = run(sink, RequireUserPermission(mockRBAC, "posts", "delete"), authenticated)
+		assert.Equal(t, http.StatusInternalServerError, code)
+
+		require.Len(t, sink.events, 2)
+		assert.Equal(t, AuditDeny, sink.events[0].Decision)
+		assert.Equal(t, "insufficient role permissions", sink.events[0].Reason)
+		assert.Equal(t, AuditError, sink.events[1].Decision)
+		assert.Equal(t, "database error", sink.events[1].Reason)
+	})
+
+	t.Run("should record unauthenticated requests", func(t *testing.T) {
+		sink := &memoryAuditSink{}
+
+		_, code := run(sink, RequirePermission(new(MockRBACService), "posts", "delete"), nil)
+
+		assert.Equal(t, http.StatusUnauthorized, code)
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, AuditDeny, sink.events[0].Decision)
+		assert.Equal(t, "user not authenticated", sink.events[0].Reason)
+	})
+
+	t.Run("should record permission sets, roles and scopes", func(t *testing.T) {
+		perms := []rbac.Permission{{Resource: "orders", Action: "read"}, {Resource: "customers", Action: "read"}}
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("CheckMultiplePermissions", "user123", perms).Return(map[rbac.Permission]bool{perms[0]: true}, nil)
+		sink := &memoryAuditSink{}
+
+		run(sink, RequireAllPermissions(mockRBAC, perms...), authenticated)
+		run(sink, RequireRoles("editor"), authenticated)
+		run(sink, RequireScopes("posts:write"), authenticated)
+
+		require.Len(t, sink.events, 3)
+		assert.Equal(t, "orders:read customers:read", sink.events[0].Resource)
+		assert.Equal(t, "all", sink.events[0].Action)
+		assert.Equal(t, "missing customers:read", sink.events[0].Reason)
+		assert.Equal(t, AuditAllow, sink.events[1].Decision)
+		assert.Equal(t, "role:editor", sink.events[1].Resource)
+		assert.Equal(t, AuditDeny, sink.events[2].Decision)
+		assert.Equal(t, "scope:posts:write", sink.events[2].Resource)
+	})
+
+	t.Run("should sample allow decisions only", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(true, nil)
+		mockRBAC.On("HasPermission", "user123", "posts", "delete").Return(false, nil)
+		sink := &memoryAuditSink{}
+
+		for i := 0; i < 10; i++ {
+			run(sink, RequirePermission(mockRBAC, "posts", "read"), authenticated, WithAllowSampleRate(0))
+			run(sink, RequirePermission(mockRBAC, "posts", "delete"), authenticated, WithAllowSampleRate(0))
+		}
+
+		require.Len(t, sink.events, 10)
+		for _, event := range sink.events {
+			assert.Equal(t, AuditDeny, event.Decision)
+		}
+	})
+
+	t.Run("should report sink errors without changing the response", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "delete").Return(true, nil)
+		sink := &memoryAuditSink{err: errors.New("disk full")}
+
+		c, code := run(sink, RequirePermission(mockRBAC, "posts", "delete"), authenticated)
+
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, c.Errors, 1)
+		assert.EqualError(t, c.Errors[0].Err, "disk full")
+	})
+
+	t.Run("should record nothing without Audit middleware", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "delete").Return(true, nil)
+		c, w := TestContext("DELETE", "/posts/1", nil)
+		authenticated(c)
+
+		RequirePermission(mockRBAC, "posts", "delete")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAuditSinks(t *testing.T) {
+	event := AuditEvent{UserID: "user123", Resource: "posts", Action: "delete", Decision: AuditDeny, Reason: "permission denied"}
+
+	t.Run("logger sink", func(t *testing.T) {
+		var buf bytes.Buffer
+		sink := NewLoggerAuditSink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		require.NoError(t, sink.Record(event))
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "user123", record["user_id"])
+		assert.Equal(t, "deny", record["decision"])
+	})
+
+	t.Run("file sink", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		sink, err := NewFileAuditSink(path)
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Record(event))
+		allow := event
+		allow.Decision = AuditAllow
+		require.NoError(t, sink.Record(allow))
+		require.NoError(t, sink.Close())
+
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		var decisions []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var recorded AuditEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &recorded))
+			decisions = append(decisions, recorded.Decision)
+		}
+		assert.Equal(t, []string{"deny", "allow"}, decisions)
+	})
+}
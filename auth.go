@@ -1,6 +1,8 @@
 package ginx
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -28,35 +30,79 @@ import (
 // }
 
 // Auth is JWT authentication middleware.
-func Auth(jwtService jwt.Service) Middleware {
+// The parsed token is available via GetToken, its claims via GetClaims and GetClaim.
+func Auth(jwtService jwt.Service, options ...Option[AuthConfig]) Middleware {
+	authenticator := JWTAuthenticator(jwtService, options...)
+
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(c *gin.Context) {
-			// Get token from Authorization header or query parameter
-			tokenString := extractToken(c)
-			if tokenString == "" {
-				c.AbortWithStatusJSON(401, gin.H{"error": "missing token"})
+			// Get token from Authorization header or query parameter, then validate and parse it
+			identity, err := authenticator.Authenticate(c)
+			if errors.Is(err, ErrNoCredentials) {
+				abortAuthFailure(c, 401, gin.H{"error": "missing token"})
 				return
 			}
-
-			// Validate and parse the token
-			parsedToken, err := jwtService.ValidateAndParse(tokenString)
 			if err != nil {
-				c.AbortWithStatusJSON(401, gin.H{"error": "invalid token"})
+				abortAuthFailure(c, 401, gin.H{"error": "invalid token"})
 				return
 			}
 
-			// Set user information to context
-			SetUserID(c, parsedToken.UserID)
-			SetUserRoles(c, parsedToken.Roles)
-			SetTokenID(c, parsedToken.TokenID)
-			SetTokenExpiresAt(c, parsedToken.ExpiresAt)
-			SetTokenIssuedAt(c, parsedToken.IssuedAt)
+			// Set user information to context, projecting custom claims
+			SetIdentity(c, identity)
 
 			next(c)
 		}
 	}
 }
 
+// jwtAuthenticator authenticates requests carrying a JWT bearer token
+type jwtAuthenticator struct {
+	service jwt.Service
+	config  *AuthConfig
+}
+
+// JWTAuthenticator creates an Authenticator for JWT bearer tokens, for use with AuthAny/AuthAll.
+// Claims mappers run when the identity is stored, as with Auth.
+func JWTAuthenticator(jwtService jwt.Service, options ...Option[AuthConfig]) Authenticator {
+	config := &AuthConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	return &jwtAuthenticator{service: jwtService, config: config}
+}
+
+// Authenticate validates the bearer token and returns its identity
+func (a *jwtAuthenticator) Authenticate(c *gin.Context) (*Identity, error) {
+	tokenString := extractToken(c)
+	if tokenString == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parsedToken, err := a.service.ValidateAndParse(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	// A token always sets the roles, even when it carries none
+	roles := parsedToken.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	return &Identity{
+		UserID: parsedToken.UserID,
+		Roles:  roles,
+		Scheme: "Bearer",
+		Token:  parsedToken,
+
+		claimsMappers: a.config.ClaimsMappers,
+	}, nil
+}
+
+// Challenge returns the Bearer challenge
+func (a *jwtAuthenticator) Challenge(c *gin.Context) string {
+	return "Bearer"
+}
+
 // extractToken extracts the JWT token from the Authorization header or query parameter.
 func extractToken(c *gin.Context) string {
 	header := c.GetHeader("Authorization")
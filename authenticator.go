@@ -0,0 +1,179 @@
+package ginx
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+)
+
+// ============================================================================
+// Authenticator - Pluggable Authentication Schemes
+// ============================================================================
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request carries no credentials for its scheme.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned by an Authenticator when the presented credentials are rejected.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity describes an authenticated principal.
+type Identity struct {
+	UserID   string     // Stored via SetUserID
+	Roles    []string   // Stored via SetUserRoles; nil leaves the roles unset
+	Scheme   string     // Authentication scheme that produced the identity, e.g. "Bearer", "Basic"
+	Token    *jwt.Token // Parsed JWT, set by JWT authentication
+	APIKeyID string     // ID of the API key, set by API key authentication
+
+	claimsMappers []ClaimsMapper // Applied to the token claims by SetIdentity
+}
+
+// Authenticator authenticates a request without writing a response.
+// Authenticate must return ErrNoCredentials when the scheme is absent from the request and
+// an error wrapping ErrInvalidCredentials when the credentials are rejected; any other
+// error is treated as an internal failure.
+type Authenticator interface {
+	Authenticate(c *gin.Context) (*Identity, error)
+	// Challenge returns the WWW-Authenticate challenge for this scheme, or "" if it has none
+	Challenge(c *gin.Context) string
+}
+
+// AuthAny accepts the request when any authenticator succeeds.
+// Authenticators are tried in order and the first identity is stored in the context.
+// When none succeed, the 401 response carries one WWW-Authenticate challenge per scheme.
+func AuthAny(authenticators ...Authenticator) Middleware {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			var failures []error
+			for _, auth := range authenticators {
+				identity, err := auth.Authenticate(c)
+				if err == nil {
+					SetIdentity(c, identity)
+					next(c)
+					return
+				}
+				failures = append(failures, err)
+			}
+
+			abortAuthentication(c, authenticators, failures)
+		}
+	}
+}
+
+// AuthAll accepts the request only when every authenticator succeeds, for example
+// an mTLS client certificate plus a user JWT. The identity of the first authenticator
+// is stored in the context; the roles, token (with its claims mappers) and API key ID
+// it lacks are taken from the later identities.
+func AuthAll(authenticators ...Authenticator) Middleware {
+	if len(authenticators) == 0 {
+		panic("AuthAll configuration error: no authenticators")
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			var merged Identity
+			for i, auth := range authenticators {
+				identity, err := auth.Authenticate(c)
+				if err != nil {
+					abortAuthentication(c, authenticators, []error{err})
+					return
+				}
+				if i == 0 {
+					merged = *identity
+				} else {
+					mergeIdentity(&merged, identity)
+				}
+			}
+
+			SetIdentity(c, &merged)
+			next(c)
+		}
+	}
+}
+
+// mergeIdentity fills the fields of identity that are still empty from other
+func mergeIdentity(identity, other *Identity) {
+	if identity.Roles == nil {
+		identity.Roles = other.Roles
+	}
+	if identity.Token == nil && other.Token != nil {
+		identity.Token = other.Token
+		identity.claimsMappers = other.claimsMappers
+	}
+	if identity.APIKeyID == "" {
+		identity.APIKeyID = other.APIKeyID
+	}
+}
+
+// SetIdentity stores an authenticated identity in the context
+func SetIdentity(c *gin.Context, identity *Identity) {
+	SetUserID(c, identity.UserID)
+	if identity.Roles != nil {
+		SetUserRoles(c, identity.Roles)
+	}
+	if identity.Scheme != "" {
+		SetAuthScheme(c, identity.Scheme)
+	}
+	if identity.Token != nil {
+		SetToken(c, identity.Token)
+		SetTokenID(c, identity.Token.TokenID)
+		SetTokenExpiresAt(c, identity.Token.ExpiresAt)
+		SetTokenIssuedAt(c, identity.Token.IssuedAt)
+	}
+	if identity.APIKeyID != "" {
+		SetAPIKeyID(c, identity.APIKeyID)
+	}
+	if len(identity.claimsMappers) > 0 {
+		if claims, ok := GetClaims(c); ok {
+			for _, mapper := range identity.claimsMappers {
+				mapper(c, claims)
+			}
+		}
+	}
+}
+
+// abortAuthentication writes the combined authentication failure response
+func abortAuthentication(c *gin.Context, authenticators []Authenticator, failures []error) {
+	invalid := false
+	for _, err := range failures {
+		switch {
+		case errors.Is(err, ErrNoCredentials):
+		case errors.Is(err, ErrInvalidCredentials):
+			invalid = true
+		default:
+			c.Error(err)
+			c.AbortWithStatusJSON(500, gin.H{"error": "authentication failed"})
+			return
+		}
+	}
+
+	for _, auth := range authenticators {
+		if challenge := auth.Challenge(c); challenge != "" {
+			c.Writer.Header().Add("WWW-Authenticate", challenge)
+		}
+	}
+
+	if invalid {
+		abortAuthFailure(c, 401, gin.H{"error": "invalid credentials"})
+		return
+	}
+	abortAuthFailure(c, 401, gin.H{"error": "authentication required"})
+}
+
+// abortAuthFailure aborts with an authentication (401) or authorization (403) failure
+// and marks the context, so Metrics counts only failures of the ginx middlewares
+func abortAuthFailure(c *gin.Context, status int, body any) {
+	c.Set(string(authFailureKey), status)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// authFailure returns the status of a failure recorded by abortAuthFailure
+func authFailure(c *gin.Context) (int, bool) {
+	status, ok := c.Get(string(authFailureKey))
+	if !ok {
+		return 0, false
+	}
+	code, ok := status.(int)
+	return code, ok
+}
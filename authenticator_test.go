@@ -0,0 +1,223 @@
+package ginx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthenticator returns a fixed identity or error
+type stubAuthenticator struct {
+	identity  *Identity
+	err       error
+	challenge string
+	calls     int
+}
+
+func (a *stubAuthenticator) Authenticate(c *gin.Context) (*Identity, error) {
+	a.calls++
+	return a.identity, a.err
+}
+
+func (a *stubAuthenticator) Challenge(c *gin.Context) string {
+	return a.challenge
+}
+
+func TestAuthAny(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should use first successful authenticator", func(t *testing.T) {
+		first := &stubAuthenticator{err: ErrNoCredentials, challenge: "Bearer"}
+		second := &stubAuthenticator{identity: &Identity{UserID: "svc-a", Roles: []string{"service"}, Scheme: "ApiKey", APIKeyID: "key1"}}
+		third := &stubAuthenticator{identity: &Identity{UserID: "other"}}
+		c, w := TestContext("GET", "/test", nil)
+
+		var userID, scheme, keyID string
+		handler := AuthAny(first, second, third)(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			scheme, _ = GetAuthScheme(c)
+			keyID, _ = GetAPIKeyID(c)
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "svc-a", userID)
+		assert.Equal(t, "ApiKey", scheme)
+		assert.Equal(t, "key1", keyID)
+		assert.Equal(t, 0, third.calls)
+	})
+
+	t.Run("should send one challenge per scheme when none succeed", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+
+		handler := AuthAny(
+			&stubAuthenticator{err: ErrNoCredentials, challenge: "Bearer"},
+			&stubAuthenticator{err: ErrNoCredentials, challenge: `Basic realm="api"`},
+			&stubAuthenticator{err: ErrNoCredentials},
+		)(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, []string{"Bearer", `Basic realm="api"`}, w.Header().Values("WWW-Authenticate"))
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "authentication required", response["error"])
+	})
+
+	t.Run("should report invalid credentials when a scheme rejects them", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+
+		handler := AuthAny(
+			&stubAuthenticator{err: ErrNoCredentials, challenge: "Bearer"},
+			&stubAuthenticator{err: ErrInvalidCredentials, challenge: "Basic"},
+		)(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "invalid credentials", response["error"])
+	})
+
+	t.Run("should return 500 on internal authenticator failure", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+
+		handler := AuthAny(
+			&stubAuthenticator{err: errors.New("store down")},
+		)(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Len(t, c.Errors, 1)
+	})
+
+	t.Run("should combine built-in authenticators", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		store := NewMemoryKeyStore()
+		store.Add("valid-key", APIKey{ID: "key1", UserID: "svc-a"})
+		auth := AuthAny(JWTAuthenticator(mockJWT), APIKeyAuthenticator(store), BasicAuthenticator(func(u, p string) bool { return false }))
+
+		// API key succeeds, JWT is not presented
+		c, w := TestContext("GET", "/test", map[string]string{"X-API-Key": "valid-key"})
+		var userID string
+		auth(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			c.Status(http.StatusOK)
+		})(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "svc-a", userID)
+
+		// Nothing presented
+		c, w = TestContext("GET", "/test", nil)
+		auth(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, []string{"Bearer", `ApiKey header="X-API-Key"`, `Basic realm="Restricted", charset="UTF-8"`},
+			w.Header().Values("WWW-Authenticate"))
+
+		mockJWT.AssertExpectations(t)
+	})
+
+	t.Run("should fill token context from JWT authenticator", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		expiresAt := time.Now().Add(time.Hour)
+		mockJWT.On("ValidateAndParse", "good").Return(&jwt.Token{
+			UserID: "user1", Roles: []string{"admin"}, TokenID: "tok1", ExpiresAt: expiresAt,
+		}, nil)
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization": "Bearer good"})
+
+		var tokenID string
+		AuthAny(JWTAuthenticator(mockJWT))(func(c *gin.Context) {
+			tokenID, _ = GetTokenID(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "tok1", tokenID)
+		mockJWT.AssertExpectations(t)
+	})
+}
+
+func TestAuthAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should require every authenticator", func(t *testing.T) {
+		first := &stubAuthenticator{identity: &Identity{UserID: "user1", Scheme: "Bearer"}}
+		second := &stubAuthenticator{identity: &Identity{UserID: "svc-a", Scheme: "ApiKey"}}
+		c, w := TestContext("GET", "/test", nil)
+
+		var userID string
+		AuthAll(first, second)(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "user1", userID)
+		assert.Equal(t, 1, second.calls)
+	})
+
+	t.Run("should reject when any authenticator fails", func(t *testing.T) {
+		first := &stubAuthenticator{identity: &Identity{UserID: "user1"}, challenge: "Bearer"}
+		second := &stubAuthenticator{err: ErrNoCredentials, challenge: "ApiKey"}
+		c, w := TestContext("GET", "/test", nil)
+
+		nextCalled := false
+		AuthAll(first, second)(func(c *gin.Context) {
+			nextCalled = true
+		})(c)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, []string{"Bearer", "ApiKey"}, w.Header().Values("WWW-Authenticate"))
+		_, exists := GetUserID(c)
+		assert.False(t, exists)
+	})
+	t.Run("should keep the token of a later JWT authenticator", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		raw := rawTestToken(t, map[string]any{"user_id": "user1", "scope": "orders:read", "tenant": "acme"})
+		mockJWT.On("ValidateAndParse", "tok").Return(&jwt.Token{UserID: "user1", Roles: []string{"user"}, Raw: raw}, nil)
+		cert := &stubAuthenticator{identity: &Identity{UserID: "svc-a", Scheme: "mTLS"}}
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization": "Bearer tok"})
+
+		var userID, tenant string
+		var roles []string
+		AuthAll(cert, JWTAuthenticator(mockJWT, WithClaimsMapper(func(c *gin.Context, claims map[string]any) {
+			tenant, _ = claims["tenant"].(string)
+		})))(RequireScopes("orders:read")(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			roles, _ = GetUserRoles(c)
+			c.Status(http.StatusOK)
+		}))(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "svc-a", userID)
+		assert.Equal(t, []string{"user"}, roles)
+		assert.Equal(t, "acme", tenant)
+		mockJWT.AssertExpectations(t)
+	})
+
+	t.Run("should panic without authenticators", func(t *testing.T) {
+		assert.PanicsWithValue(t, "AuthAll configuration error: no authenticators", func() {
+			AuthAll()
+		})
+	})
+}
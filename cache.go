@@ -47,6 +47,7 @@ func cacheWithGroup(cache shardedcache.CacheInterface, groupName string) Middlew
 				response, exists = shardedcache.GetTyped[cachedResponse](cache, key)
 			}
 
+			c.Set(string(cacheHitKey), exists)
 			if exists {
 				// Set headers first, then status code, then write body
 				for k, v := range response.Headers {
@@ -149,7 +150,17 @@ func (w *responseWriter) cacheResponse() {
 	}
 }
 
+// generateCacheKey builds the cache key from method, path and query.
+// Requests of a tenant (see Tenant) get keys scoped to it, so tenants never share responses.
 func generateCacheKey(c *gin.Context) string {
+	if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
+		return "tenant:" + tenantID + "|" + requestCacheKey(c)
+	}
+	return requestCacheKey(c)
+}
+
+// requestCacheKey builds the tenant-independent part of the cache key
+func requestCacheKey(c *gin.Context) string {
 	method := c.Request.Method
 	path := c.Request.URL.Path
 	query := c.Request.URL.RawQuery
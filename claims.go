@@ -0,0 +1,163 @@
+package ginx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+)
+
+// ============================================================================
+// JWT Claims - Full Token and Custom Claims Access
+// ============================================================================
+
+// ClaimsMapper projects claims of a validated token into the context,
+// e.g. a tenant ID or session ID into application-defined keys.
+type ClaimsMapper func(c *gin.Context, claims map[string]any)
+
+// AuthConfig JWT authentication configuration
+type AuthConfig struct {
+	ClaimsMappers []ClaimsMapper // Applied in order after the token is validated
+}
+
+// WithClaimsMapper adds a claims mapper to the Auth middleware or JWTAuthenticator
+func WithClaimsMapper(mapper ClaimsMapper) Option[AuthConfig] {
+	return func(c *AuthConfig) {
+		c.ClaimsMappers = append(c.ClaimsMappers, mapper)
+	}
+}
+
+// GetClaims returns all claims of the validated token, including custom ones.
+// Claims are decoded from the raw token on first access and cached in the context.
+// Numbers are kept as json.Number; use GetClaim for typed access.
+func GetClaims(c *gin.Context) (map[string]any, bool) {
+	token, ok := GetToken(c)
+	if !ok {
+		return nil, false
+	}
+	if value, exists := c.Get(string(tokenClaimsKey)); exists {
+		if cached, ok := value.(cachedClaims); ok && cached.token == token {
+			return cached.claims, true
+		}
+	}
+
+	claims, err := decodeClaims(token.Raw)
+	if err != nil {
+		return nil, false
+	}
+	c.Set(string(tokenClaimsKey), cachedClaims{token: token, claims: claims})
+	return claims, true
+}
+
+// cachedClaims ties decoded claims to the token they were decoded from
+type cachedClaims struct {
+	token  *jwt.Token
+	claims map[string]any
+}
+
+// GetClaim returns a single claim converted to T.
+// Besides exact types, it converts JSON numbers to Go numeric types and time.Time
+// (NumericDate seconds), and JSON arrays of strings to []string.
+func GetClaim[T any](c *gin.Context, name string) (T, bool) {
+	var zero T
+	claims, ok := GetClaims(c)
+	if !ok {
+		return zero, false
+	}
+	value, exists := claims[name]
+	if !exists {
+		return zero, false
+	}
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	return convertClaim[T](value)
+}
+
+// decodeClaims decodes the payload segment of a compact JWT without verifying it.
+// It must only be called for tokens that were already validated.
+func decodeClaims(raw string) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// convertClaim converts JSON-decoded claim values to common Go types
+func convertClaim[T any](value any) (T, bool) {
+	var result T
+	switch target := any(&result).(type) {
+	case *int:
+		n, ok := claimInt64(value)
+		*target = int(n)
+		return result, ok
+	case *int64:
+		n, ok := claimInt64(value)
+		*target = n
+		return result, ok
+	case *float64:
+		num, ok := value.(json.Number)
+		if !ok {
+			return result, false
+		}
+		f, err := num.Float64()
+		*target = f
+		return result, err == nil
+	case *time.Time:
+		n, ok := claimInt64(value)
+		if !ok {
+			return result, false
+		}
+		*target = time.Unix(n, 0)
+		return result, true
+	case *[]string:
+		items, ok := value.([]any)
+		if !ok {
+			return result, false
+		}
+		strs := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return result, false
+			}
+			strs = append(strs, s)
+		}
+		*target = strs
+		return result, true
+	}
+	return result, false
+}
+
+// claimInt64 converts an integral JSON number claim to int64
+func claimInt64(value any) (int64, bool) {
+	num, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if n, err := num.Int64(); err == nil {
+		return n, true
+	}
+	f, err := num.Float64()
+	if err != nil || f != float64(int64(f)) {
+		return 0, false
+	}
+	return int64(f), true
+}
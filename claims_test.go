@@ -0,0 +1,203 @@
+package ginx
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawTestToken builds a compact JWT with the given payload (signature is not checked by claims helpers)
+func rawTestToken(t *testing.T, payload map[string]any) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return header + "." + base64.RawURLEncoding.EncodeToString(body) + ".signature"
+}
+
+func TestGetClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	raw := rawTestToken(t, map[string]any{
+		"user_id":    "user123",
+		"tenant_id":  "acme",
+		"scope":      "orders:read orders:write",
+		"groups":     []string{"eng", "ops"},
+		"session_no": 42,
+		"ratio":      0.5,
+		"login_at":   1700000000,
+		"beta":       true,
+	})
+
+	t.Run("should return false without token", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+
+		claims, ok := GetClaims(c)
+		assert.False(t, ok)
+		assert.Nil(t, claims)
+
+		_, ok = GetClaim[string](c, "tenant_id")
+		assert.False(t, ok)
+	})
+
+	t.Run("should expose custom claims", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: raw})
+
+		claims, ok := GetClaims(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", claims["tenant_id"])
+	})
+
+	t.Run("should convert typed claims", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: raw})
+
+		tenant, ok := GetClaim[string](c, "tenant_id")
+		assert.True(t, ok)
+		assert.Equal(t, "acme", tenant)
+
+		groups, ok := GetClaim[[]string](c, "groups")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"eng", "ops"}, groups)
+
+		n, ok := GetClaim[int](c, "session_no")
+		assert.True(t, ok)
+		assert.Equal(t, 42, n)
+
+		n64, ok := GetClaim[int64](c, "session_no")
+		assert.True(t, ok)
+		assert.Equal(t, int64(42), n64)
+
+		ratio, ok := GetClaim[float64](c, "ratio")
+		assert.True(t, ok)
+		assert.Equal(t, 0.5, ratio)
+
+		loginAt, ok := GetClaim[time.Time](c, "login_at")
+		assert.True(t, ok)
+		assert.Equal(t, int64(1700000000), loginAt.Unix())
+
+		beta, ok := GetClaim[bool](c, "beta")
+		assert.True(t, ok)
+		assert.True(t, beta)
+	})
+
+	t.Run("should report mismatched types and missing claims", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: raw})
+
+		_, ok := GetClaim[int](c, "tenant_id")
+		assert.False(t, ok)
+
+		_, ok = GetClaim[int](c, "ratio")
+		assert.False(t, ok)
+
+		_, ok = GetClaim[string](c, "missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("should refresh claims when token changes", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: raw})
+		tenant, _ := GetClaim[string](c, "tenant_id")
+		assert.Equal(t, "acme", tenant)
+
+		SetToken(c, &jwt.Token{UserID: "user456", Raw: rawTestToken(t, map[string]any{"tenant_id": "globex"})})
+		tenant, _ = GetClaim[string](c, "tenant_id")
+		assert.Equal(t, "globex", tenant)
+	})
+
+	t.Run("should fail on malformed raw token", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: "not-a-jwt"})
+
+		_, ok := GetClaims(c)
+		assert.False(t, ok)
+	})
+}
+
+func TestAuthClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should expose parsed token from a real JWT service", func(t *testing.T) {
+		service, err := jwt.New("a-very-long-test-secret-key-for-ginx-tests")
+		require.NoError(t, err)
+		defer service.Close()
+
+		tokenString, err := service.GenerateToken("user123", []string{"admin"}, time.Hour)
+		require.NoError(t, err)
+
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization": "Bearer " + tokenString})
+
+		var token *jwt.Token
+		var userID string
+		Auth(service)(func(c *gin.Context) {
+			token, _ = GetToken(c)
+			userID, _ = GetClaim[string](c, "user_id")
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, token)
+		assert.Equal(t, tokenString, token.Raw)
+		assert.Equal(t, "user123", userID)
+	})
+
+	t.Run("should apply claims mappers", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		raw := rawTestToken(t, map[string]any{"user_id": "user123", "tenant_id": "acme", "sid": "s-1"})
+		mockJWT.On("ValidateAndParse", "tok").Return(&jwt.Token{UserID: "user123", Raw: raw}, nil)
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization": "Bearer tok"})
+
+		var tenant, session string
+		Auth(mockJWT,
+			WithClaimsMapper(func(c *gin.Context, claims map[string]any) {
+				c.Set("tenant", claims["tenant_id"])
+			}),
+			WithClaimsMapper(func(c *gin.Context, claims map[string]any) {
+				c.Set("session", claims["sid"])
+			}),
+		)(func(c *gin.Context) {
+			tenant = c.GetString("tenant")
+			session = c.GetString("session")
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "acme", tenant)
+		assert.Equal(t, "s-1", session)
+		mockJWT.AssertExpectations(t)
+	})
+
+	t.Run("should apply claims mappers through JWTAuthenticator", func(t *testing.T) {
+		mockJWT := new(MockJWTService)
+		raw := rawTestToken(t, map[string]any{"user_id": "user123", "perms": []any{"billing"}})
+		mockJWT.On("ValidateAndParse", "tok").Return(&jwt.Token{UserID: "user123", Roles: []string{"user"}, Raw: raw}, nil)
+		c, w := TestContext("GET", "/test", map[string]string{"Authorization": "Bearer tok"})
+
+		var roles []string
+		AuthAny(JWTAuthenticator(mockJWT,
+			WithClaimsMapper(func(c *gin.Context, claims map[string]any) {
+				roles, _ := GetUserRoles(c)
+				for _, perm := range claims["perms"].([]any) {
+					roles = append(roles, perm.(string))
+				}
+				SetUserRoles(c, roles)
+			}),
+		))(func(c *gin.Context) {
+			roles, _ = GetUserRoles(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"user", "billing"}, roles)
+		mockJWT.AssertExpectations(t)
+	})
+}
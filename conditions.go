@@ -131,3 +131,11 @@ func OnTimeout() Condition {
 		return c.Writer.Header().Get("X-Timeout") == "true"
 	}
 }
+
+// StatusAtLeast checks if the response status is at least code.
+// Only meaningful after the handler ran, e.g. in WithLogBodies.
+func StatusAtLeast(code int) Condition {
+	return func(c *gin.Context) bool {
+		return c.Writer.Status() >= code
+	}
+}
@@ -368,6 +368,19 @@ func TestCustomCondition(t *testing.T) {
 			t.Error("Custom condition should handle complex logic correctly")
 		}
 	})
+
+	t.Run("StatusAtLeast - response status", func(t *testing.T) {
+		c, _ := TestContext("GET", "/api/users", nil)
+
+		c.Status(404)
+
+		if !StatusAtLeast(400)(c) {
+			t.Error("StatusAtLeast(400) should match 404")
+		}
+		if StatusAtLeast(500)(c) {
+			t.Error("StatusAtLeast(500) should not match 404")
+		}
+	})
 }
 
 func TestComplexConditionCombinations(t *testing.T) {
@@ -1,26 +1,151 @@
 package ginx
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
 )
 
 // ============================================================================
 // Context Keys - Typed Context Key Management
 // ============================================================================
 
-// contextKey defines a private type for context keys to avoid conflicts
+// ContextKey is a typed key for request-scoped values.
+// Values are stored in gin's Keys under the key name and mirrored into
+// c.Request.Context(), so code receiving a plain context.Context can read them via FromContext.
+// Names share one namespace per request; prefix them, e.g. "myapp.account".
+//
+//	var accountKey = ginx.NewContextKey[*Account]("myapp.account")
+//
+//	accountKey.Set(c, account)
+//	account, ok := accountKey.Get(c)
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey creates a typed context key with the given name
+func NewContextKey[T any](name string) ContextKey[T] {
+	return ContextKey[T]{name: name}
+}
+
+// Name returns the key name used in gin's Keys
+func (k ContextKey[T]) Name() string {
+	return k.name
+}
+
+// Set stores the value in the gin context and the request context
+func (k ContextKey[T]) Set(c *gin.Context, value T) {
+	c.Set(k.name, value)
+	if c.Request != nil {
+		requestValues(c).set(k, value)
+	}
+}
+
+// Get returns the value stored in the gin context
+func (k ContextKey[T]) Get(c *gin.Context) (T, bool) {
+	var zero T
+	value, exists := c.Get(k.name)
+	if !exists {
+		return zero, false
+	}
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	return zero, false
+}
+
+// MustGet returns the value stored in the gin context and panics if it is missing
+func (k ContextKey[T]) MustGet(c *gin.Context) T {
+	value, ok := k.Get(c)
+	if !ok {
+		panic(fmt.Sprintf("ginx: context key %q is not set", k.name))
+	}
+	return value
+}
+
+// GetOr returns the value stored in the gin context, or def if it is missing
+func (k ContextKey[T]) GetOr(c *gin.Context, def T) T {
+	if value, ok := k.Get(c); ok {
+		return value
+	}
+	return def
+}
+
+// FromContext returns the value mirrored into a context.Context by Set
+func (k ContextKey[T]) FromContext(ctx context.Context) (T, bool) {
+	var zero T
+	values, ok := ctx.Value(contextValuesKey{}).(*contextValues)
+	if !ok {
+		return zero, false
+	}
+	value, ok := values.get(k).(T)
+	return value, ok
+}
+
+// contextValuesKey is the request context key of the mirrored values
+type contextValuesKey struct{}
+
+// contextValues holds the values mirrored into a request context.
+// It is attached once per request, so Set does not copy the request on every call.
+type contextValues struct {
+	mu     sync.RWMutex
+	values map[any]any
+}
+
+// requestValues returns the mirrored values of the request, attaching them on first use
+func requestValues(c *gin.Context) *contextValues {
+	if values, ok := c.Request.Context().Value(contextValuesKey{}).(*contextValues); ok {
+		return values
+	}
+	values := &contextValues{values: make(map[any]any)}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextValuesKey{}, values))
+	return values
+}
+
+func (v *contextValues) set(key, value any) {
+	v.mu.Lock()
+	v.values[key] = value
+	v.mu.Unlock()
+}
+
+func (v *contextValues) get(key any) any {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.values[key]
+}
+
+// Keys of the values ginx exposes through Set/Get helpers
+var (
+	userIDKey         = NewContextKey[string]("ginx.user_id")
+	userRolesKey      = NewContextKey[[]string]("ginx.user_roles")
+	tokenIDKey        = NewContextKey[string]("ginx.token_id")
+	tokenExpiresAtKey = NewContextKey[time.Time]("ginx.token_expires_at")
+	tokenIssuedAtKey  = NewContextKey[time.Time]("ginx.token_issued_at")
+	requestIDKey      = NewContextKey[string]("ginx.request_id")
+	apiKeyIDKey       = NewContextKey[string]("ginx.api_key_id")
+	authSchemeKey     = NewContextKey[string]("ginx.auth_scheme")
+	tokenKey          = NewContextKey[*jwt.Token]("ginx.token")
+	tenantIDKey       = NewContextKey[string]("ginx.tenant_id")
+	resourceKey       = NewContextKey[any]("ginx.resource")
+)
+
+// contextKey defines a private type for internal middleware state, kept in gin's Keys only
 type contextKey string
 
-// Define all context keys as private constants
+// Internal state keys
 const (
-	userIDKey         contextKey = "ginx.user_id"
-	userRolesKey      contextKey = "ginx.user_roles"
-	tokenIDKey        contextKey = "ginx.token_id"
-	tokenExpiresAtKey contextKey = "ginx.token_expires_at"
-	tokenIssuedAtKey  contextKey = "ginx.token_issued_at"
-	requestIDKey      contextKey = "ginx.request_id"
+	tokenClaimsKey   contextKey = "ginx.token_claims"
+	rbacDecisionsKey contextKey = "ginx.rbac_decisions"
+	auditorKey       contextKey = "ginx.auditor"
+	rbacFailureKey   contextKey = "ginx.rbac_failure"
+	rateLimitKey     contextKey = "ginx.rate_limited"
+	panicKey         contextKey = "ginx.panic"
+	cacheHitKey      contextKey = "ginx.cache_hit"
+	authFailureKey   contextKey = "ginx.auth_failure"
 )
 
 // ============================================================================
@@ -29,112 +154,158 @@ const (
 
 // SetUserID sets the user ID in the context
 func SetUserID(c *gin.Context, userID string) {
-	c.Set(string(userIDKey), userID)
+	userIDKey.Set(c, userID)
 }
 
 // GetUserID gets the user ID from the context
 func GetUserID(c *gin.Context) (string, bool) {
-	value, exists := c.Get(string(userIDKey))
-	if !exists {
-		return "", false
-	}
-	if id, ok := value.(string); ok {
-		return id, true
-	}
-	return "", false
+	return userIDKey.Get(c)
 }
 
 // SetUserRoles sets the user roles in the context
 func SetUserRoles(c *gin.Context, roles []string) {
-	c.Set(string(userRolesKey), roles)
+	userRolesKey.Set(c, roles)
 }
 
 // GetUserRoles gets the user roles from the context
 func GetUserRoles(c *gin.Context) ([]string, bool) {
-	value, exists := c.Get(string(userRolesKey))
-	if !exists {
-		return nil, false
-	}
-	if roles, ok := value.([]string); ok {
-		return roles, true
-	}
-	return nil, false
+	return userRolesKey.Get(c)
 }
 
 // ============================================================================
 // Token Context Helpers
 // ============================================================================
 
+// SetToken sets the parsed JWT in the context
+func SetToken(c *gin.Context, token *jwt.Token) {
+	tokenKey.Set(c, token)
+}
+
+// GetToken gets the parsed JWT from the context
+func GetToken(c *gin.Context) (*jwt.Token, bool) {
+	token, ok := tokenKey.Get(c)
+	return token, ok && token != nil
+}
+
 // SetTokenID sets the token ID in the context
 func SetTokenID(c *gin.Context, tokenID string) {
-	c.Set(string(tokenIDKey), tokenID)
+	tokenIDKey.Set(c, tokenID)
 }
 
 // GetTokenID gets the token ID from the context
 func GetTokenID(c *gin.Context) (string, bool) {
-	value, exists := c.Get(string(tokenIDKey))
-	if !exists {
-		return "", false
-	}
-	if id, ok := value.(string); ok {
-		return id, true
-	}
-	return "", false
+	return tokenIDKey.Get(c)
 }
 
 // SetTokenExpiresAt sets the token expiration time in the context
 func SetTokenExpiresAt(c *gin.Context, expiresAt time.Time) {
-	c.Set(string(tokenExpiresAtKey), expiresAt)
+	tokenExpiresAtKey.Set(c, expiresAt)
 }
 
 // GetTokenExpiresAt gets the token expiration time from the context
 func GetTokenExpiresAt(c *gin.Context) (time.Time, bool) {
-	value, exists := c.Get(string(tokenExpiresAtKey))
-	if !exists {
-		return time.Time{}, false
-	}
-	if t, ok := value.(time.Time); ok {
-		return t, true
-	}
-	return time.Time{}, false
+	return tokenExpiresAtKey.Get(c)
 }
 
 // SetTokenIssuedAt sets the token issued time in the context
 func SetTokenIssuedAt(c *gin.Context, issuedAt time.Time) {
-	c.Set(string(tokenIssuedAtKey), issuedAt)
+	tokenIssuedAtKey.Set(c, issuedAt)
 }
 
 // GetTokenIssuedAt gets the token issued time from the context
 func GetTokenIssuedAt(c *gin.Context) (time.Time, bool) {
-	value, exists := c.Get(string(tokenIssuedAtKey))
-	if !exists {
-		return time.Time{}, false
-	}
-	if t, ok := value.(time.Time); ok {
-		return t, true
-	}
-	return time.Time{}, false
+	return tokenIssuedAtKey.Get(c)
+}
+
+// SetAPIKeyID sets the ID of the API key used to authenticate in the context
+func SetAPIKeyID(c *gin.Context, keyID string) {
+	apiKeyIDKey.Set(c, keyID)
+}
+
+// GetAPIKeyID gets the ID of the API key used to authenticate from the context
+func GetAPIKeyID(c *gin.Context) (string, bool) {
+	return apiKeyIDKey.Get(c)
+}
+
+// SetAuthScheme sets the authentication scheme used for the request in the context
+func SetAuthScheme(c *gin.Context, scheme string) {
+	authSchemeKey.Set(c, scheme)
+}
+
+// GetAuthScheme gets the authentication scheme used for the request from the context
+func GetAuthScheme(c *gin.Context) (string, bool) {
+	return authSchemeKey.Get(c)
 }
 
 // ============================================================================
 // Request Context Helpers
 // ============================================================================
 
+// SetTenantID sets the tenant ID in the context
+func SetTenantID(c *gin.Context, tenantID string) {
+	tenantIDKey.Set(c, tenantID)
+}
+
+// GetTenantID gets the tenant ID from the context
+func GetTenantID(c *gin.Context) (string, bool) {
+	return tenantIDKey.Get(c)
+}
+
 // SetRequestID sets the request ID in the context
 func SetRequestID(c *gin.Context, id string) {
-	c.Set(string(requestIDKey), id)
+	requestIDKey.Set(c, id)
 }
 
 // GetRequestID gets the request ID from the context
 func GetRequestID(c *gin.Context) (string, bool) {
-	value, exists := c.Get(string(requestIDKey))
-	if !exists {
-		return "", false
-	}
-	if id, ok := value.(string); ok {
-		return id, true
-	}
-	return "", false
+	return requestIDKey.Get(c)
+}
+
+// SetResource sets the resource loaded for authorization in the context
+func SetResource(c *gin.Context, resource any) {
+	resourceKey.Set(c, resource)
+}
+
+// GetResource gets the resource loaded for authorization from the context
+func GetResource(c *gin.Context) (any, bool) {
+	return c.Get(resourceKey.Name())
+}
+
+// ============================================================================
+// context.Context Helpers - Identity for Code Outside Gin
+// ============================================================================
+
+// The Set helpers above also store their values in c.Request.Context(), so layers that only
+// receive a context.Context (repositories, outgoing clients, log handlers) can read them.
+
+// RequestIDFromContext gets the request ID from a request context
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return requestIDKey.FromContext(ctx)
+}
+
+// UserIDFromContext gets the user ID from a request context
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	return userIDKey.FromContext(ctx)
+}
+
+// UserRolesFromContext gets the user roles from a request context
+func UserRolesFromContext(ctx context.Context) ([]string, bool) {
+	return userRolesKey.FromContext(ctx)
+}
+
+// TenantIDFromContext gets the tenant ID from a request context
+func TenantIDFromContext(ctx context.Context) (string, bool) {
+	return tenantIDKey.FromContext(ctx)
+}
+
+// APIKeyIDFromContext gets the ID of the API key used to authenticate from a request context
+func APIKeyIDFromContext(ctx context.Context) (string, bool) {
+	return apiKeyIDKey.FromContext(ctx)
+}
+
+// AuthSchemeFromContext gets the authentication scheme from a request context
+func AuthSchemeFromContext(ctx context.Context) (string, bool) {
+	return authSchemeKey.FromContext(ctx)
 }
 
 // ============================================================================
@@ -145,7 +316,7 @@ func GetRequestID(c *gin.Context) (string, bool) {
 func GetUserIDOrAbort(c *gin.Context) (string, bool) {
 	userID, exists := GetUserID(c)
 	if !exists {
-		c.AbortWithStatusJSON(401, gin.H{"error": "user not authenticated"})
+		abortAuthFailure(c, 401, gin.H{"error": "user not authenticated"})
 		return "", false
 	}
 	return userID, true
@@ -0,0 +1,132 @@
+package ginx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type account struct{ Name string }
+	accountKey := NewContextKey[*account]("test.account")
+	limitKey := NewContextKey[int]("test.limit")
+
+	t.Run("should set and get typed values", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		accountKey.Set(c, &account{Name: "acme"})
+
+		value, ok := accountKey.Get(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", value.Name)
+		assert.Equal(t, "acme", accountKey.MustGet(c).Name)
+	})
+
+	t.Run("should report missing and mistyped values", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		c.Set("test.limit", "ten")
+
+		_, ok := limitKey.Get(c)
+		assert.False(t, ok)
+		assert.Equal(t, 10, limitKey.GetOr(c, 10))
+		assert.PanicsWithValue(t, `ginx: context key "test.account" is not set`, func() {
+			accountKey.MustGet(c)
+		})
+	})
+
+	t.Run("should mirror values into the request context", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		limitKey.Set(c, 42)
+		SetUserID(c, "user123")
+
+		ctx := c.Request.Context()
+		limit, ok := limitKey.FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, 42, limit)
+		userID, ok := userIDKey.FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "user123", userID)
+
+		_, ok = limitKey.FromContext(context.Background())
+		assert.False(t, ok)
+	})
+
+	t.Run("should attach the request context once", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		limitKey.Set(c, 1)
+		req := c.Request
+
+		SetIdentity(c, &Identity{UserID: "user123", Roles: []string{"admin"}, Scheme: "Bearer"})
+		limitKey.Set(c, 2)
+
+		assert.Same(t, req, c.Request)
+		limit, _ := limitKey.FromContext(c.Request.Context())
+		assert.Equal(t, 2, limit)
+		roles, _ := userRolesKey.FromContext(c.Request.Context())
+		assert.Equal(t, []string{"admin"}, roles)
+	})
+}
+
+func TestIdentityFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should propagate identity set by middlewares", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		NewChain().
+			Use(RequestID()).
+			Use(func(next gin.HandlerFunc) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					SetUserID(c, "user123")
+					SetUserRoles(c, []string{"editor"})
+					SetTenantID(c, "acme")
+					SetAuthScheme(c, "Bearer")
+					next(c)
+				}
+			}).
+			Build()(c)
+		ctx := c.Request.Context()
+
+		requestID, ok := RequestIDFromContext(ctx)
+		assert.True(t, ok)
+		assert.NotEmpty(t, requestID)
+		userID, _ := UserIDFromContext(ctx)
+		assert.Equal(t, "user123", userID)
+		roles, _ := UserRolesFromContext(ctx)
+		assert.Equal(t, []string{"editor"}, roles)
+		tenantID, _ := TenantIDFromContext(ctx)
+		assert.Equal(t, "acme", tenantID)
+		scheme, _ := AuthSchemeFromContext(ctx)
+		assert.Equal(t, "Bearer", scheme)
+	})
+
+	t.Run("should keep deadlines of earlier middlewares", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		var ctx context.Context
+		NewChain().
+			Use(Timeout(WithTimeout(time.Second))).
+			Use(RequestID()).
+			Use(func(next gin.HandlerFunc) gin.HandlerFunc {
+				return func(c *gin.Context) {
+					ctx = c.Request.Context()
+					next(c)
+				}
+			}).
+			Build()(c)
+
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		_, ok := RequestIDFromContext(ctx)
+		assert.True(t, ok)
+	})
+
+	t.Run("should report missing values", func(t *testing.T) {
+		_, ok := UserIDFromContext(context.Background())
+		assert.False(t, ok)
+		_, ok = APIKeyIDFromContext(context.Background())
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,533 @@
+package ginx
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ============================================================================
+// HTTP Authentication Configuration
+// ============================================================================
+
+// BasicVerifier checks a username/password pair and reports whether it is valid.
+type BasicVerifier func(username, password string) bool
+
+// DigestSecretFunc returns the password of the given user in the given realm.
+// Digest authentication needs the shared secret to recompute the client response.
+type DigestSecretFunc func(username, realm string) (password string, ok bool)
+
+// Digest algorithms supported by DigestAuth (RFC 7616)
+const (
+	DigestSHA256     = "SHA-256"
+	DigestSHA256Sess = "SHA-256-sess"
+	DigestMD5        = "MD5"
+	DigestMD5Sess    = "MD5-sess"
+)
+
+// HTTPAuthConfig holds configuration shared by the BasicAuth and DigestAuth middlewares
+type HTTPAuthConfig struct {
+	Realm             string                         // Protection space sent in the challenge, defaults to "Restricted"
+	Roles             func(username string) []string // Optional roles lookup, stored via SetUserRoles
+	DigestAlgorithms  []string                       // Digest algorithms offered, one challenge each (default: SHA-256, MD5)
+	DigestNonceExpiry time.Duration                  // Digest nonce lifetime, defaults to 5 minutes
+	DigestMaxNonces   int                            // Nonces whose counts are tracked at once, defaults to 10000
+}
+
+// defaultHTTPAuthConfig returns default HTTP authentication configuration
+func defaultHTTPAuthConfig() *HTTPAuthConfig {
+	return &HTTPAuthConfig{
+		Realm:             "Restricted",
+		DigestAlgorithms:  []string{DigestSHA256, DigestMD5},
+		DigestNonceExpiry: 5 * time.Minute,
+		DigestMaxNonces:   10000,
+	}
+}
+
+// WithRealm sets the authentication realm
+func WithRealm(realm string) Option[HTTPAuthConfig] {
+	return func(c *HTTPAuthConfig) {
+		c.Realm = realm
+	}
+}
+
+// WithAuthRoles sets the roles lookup used to fill SetUserRoles after successful authentication
+func WithAuthRoles(roles func(username string) []string) Option[HTTPAuthConfig] {
+	return func(c *HTTPAuthConfig) {
+		c.Roles = roles
+	}
+}
+
+// WithDigestAlgorithms sets the digest algorithms offered to clients, in order of preference
+func WithDigestAlgorithms(algorithms ...string) Option[HTTPAuthConfig] {
+	return func(c *HTTPAuthConfig) {
+		c.DigestAlgorithms = algorithms
+	}
+}
+
+// WithDigestMaxNonces bounds the nonces whose request counts are tracked.
+// When all slots hold unexpired nonces, new nonces are answered with stale=true.
+func WithDigestMaxNonces(n int) Option[HTTPAuthConfig] {
+	return func(c *HTTPAuthConfig) {
+		c.DigestMaxNonces = n
+	}
+}
+
+// WithDigestNonceExpiry sets how long a digest nonce stays valid
+func WithDigestNonceExpiry(expiry time.Duration) Option[HTTPAuthConfig] {
+	return func(c *HTTPAuthConfig) {
+		c.DigestNonceExpiry = expiry
+	}
+}
+
+// ============================================================================
+// Middleware - HTTP Basic Authentication
+// ============================================================================
+
+// BasicAuth is HTTP Basic authentication middleware (RFC 7617).
+// Credentials are checked by verifier; on success the username is stored via SetUserID.
+func BasicAuth(verifier BasicVerifier, options ...Option[HTTPAuthConfig]) Middleware {
+	authenticator := BasicAuthenticator(verifier, options...)
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			identity, err := authenticator.Authenticate(c)
+			if err != nil {
+				c.Header("WWW-Authenticate", authenticator.Challenge(c))
+				if errors.Is(err, ErrNoCredentials) {
+					abortAuthFailure(c, 401, gin.H{"error": "missing credentials"})
+				} else {
+					abortAuthFailure(c, 401, gin.H{"error": "invalid credentials"})
+				}
+				return
+			}
+
+			SetIdentity(c, identity)
+			next(c)
+		}
+	}
+}
+
+// basicAuthenticator authenticates requests carrying Basic credentials
+type basicAuthenticator struct {
+	verifier  BasicVerifier
+	config    *HTTPAuthConfig
+	challenge string
+}
+
+// BasicAuthenticator creates an Authenticator for HTTP Basic credentials, for use with AuthAny/AuthAll.
+func BasicAuthenticator(verifier BasicVerifier, options ...Option[HTTPAuthConfig]) Authenticator {
+	config := defaultHTTPAuthConfig()
+	for _, option := range options {
+		option(config)
+	}
+	return &basicAuthenticator{
+		verifier:  verifier,
+		config:    config,
+		challenge: basicChallenge(config.Realm),
+	}
+}
+
+// Authenticate verifies the Basic credentials and returns the user identity
+func (a *basicAuthenticator) Authenticate(c *gin.Context) (*Identity, error) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	if !a.verifier(username, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return httpAuthIdentity(a.config, username, "Basic"), nil
+}
+
+// Challenge returns the Basic challenge with the configured realm
+func (a *basicAuthenticator) Challenge(c *gin.Context) string {
+	return a.challenge
+}
+
+// basicChallenge builds the WWW-Authenticate value for Basic authentication
+func basicChallenge(realm string) string {
+	return `Basic realm=` + strconv.Quote(realm) + `, charset="UTF-8"`
+}
+
+// httpAuthIdentity builds the identity of a user authenticated by Basic or Digest
+func httpAuthIdentity(config *HTTPAuthConfig, username, scheme string) *Identity {
+	identity := &Identity{UserID: username, Scheme: scheme}
+	if config.Roles != nil {
+		identity.Roles = config.Roles(username)
+	}
+	return identity
+}
+
+// ============================================================================
+// Basic Verifiers - bcrypt and htpasswd
+// ============================================================================
+
+// dummyBcryptHash is compared against for unknown users so that response
+// timing does not reveal whether a username exists.
+// It uses bcrypt.DefaultCost so the comparison takes as long as a typical real one.
+var dummyBcryptHash = []byte("$2a$10$uZ5QT8X5wPYW/BaYFabXjOmfUoUKiKIgoK/xVvdRpO52SZwPS8B8q")
+
+// BcryptVerifier creates a verifier from a map of username to bcrypt hash.
+func BcryptVerifier(users map[string]string) BasicVerifier {
+	hashes := make(map[string][]byte, len(users))
+	for user, h := range users {
+		hashes[user] = []byte(h)
+	}
+	return func(username, password string) bool {
+		h, exists := hashes[username]
+		if !exists {
+			bcrypt.CompareHashAndPassword(dummyBcryptHash, []byte(password))
+			return false
+		}
+		return bcrypt.CompareHashAndPassword(h, []byte(password)) == nil
+	}
+}
+
+// HtpasswdVerifier creates a verifier from htpasswd-formatted content ("user:hash" per line).
+// Supported hash formats are bcrypt ($2y$, $2a$, $2b$) and SHA-1 ({SHA}).
+func HtpasswdVerifier(r io.Reader) (BasicVerifier, error) {
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, h, found := strings.Cut(line, ":")
+		if !found || user == "" || h == "" {
+			return nil, fmt.Errorf("htpasswd: malformed entry on line %d", lineNo)
+		}
+		if !isBcryptHash(h) && !strings.HasPrefix(h, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd: unsupported hash format for user %q on line %d", user, lineNo)
+		}
+		entries[user] = h
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("htpasswd: %w", err)
+	}
+
+	return func(username, password string) bool {
+		h, exists := entries[username]
+		if !exists {
+			bcrypt.CompareHashAndPassword(dummyBcryptHash, []byte(password))
+			return false
+		}
+		if isBcryptHash(h) {
+			return bcrypt.CompareHashAndPassword([]byte(h), []byte(password)) == nil
+		}
+		sum := sha1.Sum([]byte(password))
+		expected := "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(h), []byte(expected)) == 1
+	}, nil
+}
+
+// HtpasswdFileVerifier creates a verifier from an htpasswd file.
+func HtpasswdFileVerifier(path string) (BasicVerifier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("htpasswd: %w", err)
+	}
+	defer f.Close()
+	return HtpasswdVerifier(f)
+}
+
+// isBcryptHash checks if the hash uses one of the bcrypt prefixes
+func isBcryptHash(h string) bool {
+	return strings.HasPrefix(h, "$2y$") || strings.HasPrefix(h, "$2a$") || strings.HasPrefix(h, "$2b$")
+}
+
+// ============================================================================
+// Middleware - HTTP Digest Authentication
+// ============================================================================
+
+// DigestAuth is HTTP Digest authentication middleware (RFC 7616, qop=auth).
+// Nonces are HMAC-signed timestamps and expire after DigestNonceExpiry; expired nonces are
+// answered with stale=true so clients can retry transparently. The nonce count (nc) of each
+// nonce must increase, so a captured request cannot be replayed.
+func DigestAuth(secrets DigestSecretFunc, options ...Option[HTTPAuthConfig]) Middleware {
+	config := defaultHTTPAuthConfig()
+	for _, option := range options {
+		option(config)
+	}
+	if len(config.DigestAlgorithms) == 0 {
+		config.DigestAlgorithms = []string{DigestSHA256, DigestMD5}
+	}
+	for _, alg := range config.DigestAlgorithms {
+		if digestHash(alg) == nil {
+			panic("DigestAuth configuration error: unsupported algorithm " + alg)
+		}
+	}
+	if config.DigestNonceExpiry <= 0 {
+		config.DigestNonceExpiry = 5 * time.Minute
+	}
+	if config.DigestMaxNonces <= 0 {
+		config.DigestMaxNonces = 10000
+	}
+
+	d := &digestAuth{config: config, secrets: secrets, key: make([]byte, 32), counts: make(map[string]digestNonceCount)}
+	if _, err := rand.Read(d.key); err != nil {
+		panic("failed to create digest nonce key: " + err.Error())
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			header := c.GetHeader("Authorization")
+			if !strings.HasPrefix(header, "Digest ") {
+				d.challenge(c, false)
+				abortAuthFailure(c, 401, gin.H{"error": "missing credentials"})
+				return
+			}
+
+			params := parseAuthParams(strings.TrimPrefix(header, "Digest "))
+			username, stale, ok := d.verify(c, params)
+			if !ok {
+				d.challenge(c, stale)
+				abortAuthFailure(c, 401, gin.H{"error": "invalid credentials"})
+				return
+			}
+
+			SetIdentity(c, httpAuthIdentity(config, username, "Digest"))
+			next(c)
+		}
+	}
+}
+
+// digestAuth holds the state of a DigestAuth middleware instance
+type digestAuth struct {
+	config  *HTTPAuthConfig
+	secrets DigestSecretFunc
+	key     []byte // HMAC key for signed nonces
+
+	mu     sync.Mutex
+	counts map[string]digestNonceCount // Last nonce count per used nonce
+}
+
+// digestNonceCount is the last accepted nonce count of a nonce
+type digestNonceCount struct {
+	nc      uint64
+	expires time.Time
+}
+
+// challenge sets one WWW-Authenticate header per configured algorithm
+func (d *digestAuth) challenge(c *gin.Context, stale bool) {
+	nonce := d.newNonce(time.Now())
+	for _, alg := range d.config.DigestAlgorithms {
+		c.Writer.Header().Add("WWW-Authenticate", d.challengeValue(alg, nonce, stale))
+	}
+}
+
+// challengeValue builds a single Digest challenge
+func (d *digestAuth) challengeValue(alg, nonce string, stale bool) string {
+	value := fmt.Sprintf(`Digest realm=%s, qop="auth", algorithm=%s, nonce=%s`,
+		strconv.Quote(d.config.Realm), alg, strconv.Quote(nonce))
+	if stale {
+		value += ", stale=true"
+	}
+	return value
+}
+
+// newNonce creates a nonce that embeds its creation time and an HMAC over it
+func (d *digestAuth) newNonce(now time.Time) string {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(now.UnixNano()))
+	mac := hmac.New(sha256.New, d.key)
+	mac.Write(ts[:])
+	mac.Write([]byte(d.config.Realm))
+	return base64.RawURLEncoding.EncodeToString(append(ts[:], mac.Sum(nil)[:16]...))
+}
+
+// checkNonce validates the nonce signature and returns its expiry
+func (d *digestAuth) checkNonce(nonce string) (expires time.Time, valid bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(raw) != 24 {
+		return time.Time{}, false
+	}
+	mac := hmac.New(sha256.New, d.key)
+	mac.Write(raw[:8])
+	mac.Write([]byte(d.config.Realm))
+	if !hmac.Equal(raw[8:], mac.Sum(nil)[:16]) {
+		return time.Time{}, false
+	}
+	issued := time.Unix(0, int64(binary.BigEndian.Uint64(raw[:8])))
+	return issued.Add(d.config.DigestNonceExpiry), true
+}
+
+// useNonce records the nonce count of an authenticated request.
+// It rejects counts that do not increase, and reports full when no slot is free.
+func (d *digestAuth) useNonce(nonce string, nc uint64, expires time.Time) (accepted, full bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.counts[nonce]; ok {
+		if nc <= last.nc {
+			return false, false
+		}
+		d.counts[nonce] = digestNonceCount{nc: nc, expires: expires}
+		return true, false
+	}
+	if len(d.counts) >= d.config.DigestMaxNonces {
+		now := time.Now()
+		for key, count := range d.counts {
+			if now.After(count.expires) {
+				delete(d.counts, key)
+			}
+		}
+		if len(d.counts) >= d.config.DigestMaxNonces {
+			return false, true
+		}
+	}
+	d.counts[nonce] = digestNonceCount{nc: nc, expires: expires}
+	return true, false
+}
+
+// verify checks the Digest credentials and returns the authenticated username.
+// stale reports that the credentials were correct for an expired nonce.
+func (d *digestAuth) verify(c *gin.Context, params map[string]string) (username string, stale, ok bool) {
+	username = params["username"]
+	nonce := params["nonce"]
+	response := params["response"]
+	if username == "" || nonce == "" || response == "" {
+		return "", false, false
+	}
+	if params["realm"] != d.config.Realm || params["qop"] != "auth" || params["cnonce"] == "" || params["nc"] == "" {
+		return "", false, false
+	}
+	if params["uri"] != c.Request.RequestURI {
+		return "", false, false
+	}
+
+	alg := params["algorithm"]
+	if alg == "" {
+		alg = DigestMD5
+	}
+	if !containsFold(d.config.DigestAlgorithms, alg) {
+		return "", false, false
+	}
+
+	nc, err := strconv.ParseUint(params["nc"], 16, 64)
+	if err != nil {
+		return "", false, false
+	}
+	expires, valid := d.checkNonce(nonce)
+	if !valid {
+		return "", false, false
+	}
+
+	password, found := d.secrets(username, d.config.Realm)
+	if !found {
+		return "", false, false
+	}
+
+	expected := digestResponse(alg, username, d.config.Realm, password, c.Request.Method, params)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(response))) != 1 {
+		return "", false, false
+	}
+	if time.Now().After(expires) {
+		return "", true, false
+	}
+	accepted, full := d.useNonce(nonce, nc, expires)
+	if !accepted {
+		return "", full, false
+	}
+	return username, false, true
+}
+
+// digestResponse computes the expected request-digest for qop=auth
+func digestResponse(alg, username, realm, password, method string, params map[string]string) string {
+	h := func(s string) string {
+		hh := digestHash(alg)()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	ha1 := h(username + ":" + realm + ":" + password)
+	if strings.HasSuffix(strings.ToLower(alg), "-sess") {
+		ha1 = h(ha1 + ":" + params["nonce"] + ":" + params["cnonce"])
+	}
+	ha2 := h(method + ":" + params["uri"])
+	return h(ha1 + ":" + params["nonce"] + ":" + params["nc"] + ":" + params["cnonce"] + ":" + params["qop"] + ":" + ha2)
+}
+
+// digestHash returns the hash constructor for a digest algorithm, or nil if unsupported
+func digestHash(alg string) func() hash.Hash {
+	switch strings.ToUpper(alg) {
+	case DigestSHA256, strings.ToUpper(DigestSHA256Sess):
+		return sha256.New
+	case DigestMD5, strings.ToUpper(DigestMD5Sess):
+		return md5.New
+	}
+	return nil
+}
+
+// containsFold checks if list contains value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAuthParams parses a comma-separated list of auth-params (key=value or key="value").
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			// Quoted string with backslash escapes
+			var b strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				b.WriteByte(s[i])
+			}
+			value = b.String()
+			if i < len(s) {
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = value
+	}
+	return params
+}
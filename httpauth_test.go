@@ -0,0 +1,340 @@
+package ginx
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func mustBcrypt(t *testing.T, password string) string {
+	t.Helper()
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(h)
+}
+
+func TestBasicAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier := func(username, password string) bool {
+		return username == "admin" && password == "s3cret"
+	}
+
+	t.Run("should authenticate valid credentials", func(t *testing.T) {
+		c, w := TestContext("GET", "/admin", nil)
+		c.Request.SetBasicAuth("admin", "s3cret")
+
+		var userID string
+		var roles []string
+		handler := BasicAuth(verifier, WithAuthRoles(func(username string) []string {
+			return []string{"admin"}
+		}))(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			roles, _ = GetUserRoles(c)
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "admin", userID)
+		assert.Equal(t, []string{"admin"}, roles)
+	})
+
+	t.Run("should leave roles unset without a roles lookup", func(t *testing.T) {
+		c, w := TestContext("GET", "/admin", nil)
+		c.Request.SetBasicAuth("admin", "s3cret")
+
+		var rolesSet bool
+		BasicAuth(verifier)(func(c *gin.Context) {
+			_, rolesSet = GetUserRoles(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, rolesSet)
+	})
+
+	t.Run("should challenge when credentials are missing", func(t *testing.T) {
+		c, w := TestContext("GET", "/admin", nil)
+
+		handler := BasicAuth(verifier, WithRealm("Admin Area"))(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="Admin Area", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, w.Body.String(), "missing credentials")
+	})
+
+	t.Run("should reject invalid credentials", func(t *testing.T) {
+		c, w := TestContext("GET", "/admin", nil)
+		c.Request.SetBasicAuth("admin", "wrong")
+
+		handler := BasicAuth(verifier)(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="Restricted", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, w.Body.String(), "invalid credentials")
+	})
+}
+
+func TestBcryptVerifier(t *testing.T) {
+	verifier := BcryptVerifier(map[string]string{"alice": mustBcrypt(t, "pw")})
+
+	assert.True(t, verifier("alice", "pw"))
+	assert.False(t, verifier("alice", "nope"))
+	assert.False(t, verifier("bob", "pw"))
+}
+
+func TestHtpasswdVerifier(t *testing.T) {
+	t.Run("should support bcrypt and SHA entries", func(t *testing.T) {
+		content := "# users\n" +
+			"alice:" + strings.Replace(mustBcrypt(t, "alice-pw"), "$2a$", "$2y$", 1) + "\n" +
+			"\n" +
+			"bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n" // password
+		verifier, err := HtpasswdVerifier(strings.NewReader(content))
+		require.NoError(t, err)
+
+		assert.True(t, verifier("alice", "alice-pw"))
+		assert.False(t, verifier("alice", "password"))
+		assert.True(t, verifier("bob", "password"))
+		assert.False(t, verifier("bob", "alice-pw"))
+		assert.False(t, verifier("carol", "password"))
+	})
+
+	t.Run("should reject unsupported hash formats", func(t *testing.T) {
+		_, err := HtpasswdVerifier(strings.NewReader("alice:$apr1$abc$def\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject malformed entries", func(t *testing.T) {
+		_, err := HtpasswdVerifier(strings.NewReader("alice\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should load htpasswd file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".htpasswd")
+		require.NoError(t, os.WriteFile(path, []byte("bob:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\n"), 0o600))
+
+		verifier, err := HtpasswdFileVerifier(path)
+		require.NoError(t, err)
+		assert.True(t, verifier("bob", "password"))
+
+		_, err = HtpasswdFileVerifier(filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
+
+// digestAuthorization computes a client Authorization header for the given challenge
+func digestAuthorization(challenge, username, password, method, uri string) string {
+	return digestAuthorizationCount(challenge, username, password, method, uri, "00000001")
+}
+
+// digestAuthorizationCount computes a client Authorization header with the given nonce count
+func digestAuthorizationCount(challenge, username, password, method, uri, nc string) string {
+	params := parseAuthParams(strings.TrimPrefix(challenge, "Digest "))
+	alg := params["algorithm"]
+	var newHash func() hash.Hash = md5.New
+	if strings.HasPrefix(alg, "SHA-256") {
+		newHash = sha256.New
+	}
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+	cnonce := "0a4f113b"
+	ha1 := h(username + ":" + params["realm"] + ":" + password)
+	ha2 := h(method + ":" + uri)
+	response := h(ha1 + ":" + params["nonce"] + ":" + nc + ":" + cnonce + ":auth:" + ha2)
+	return `Digest username="` + username + `", realm="` + params["realm"] + `", nonce="` + params["nonce"] +
+		`", uri="` + uri + `", algorithm=` + alg + `, qop=auth, nc=` + nc + `, cnonce="` + cnonce +
+		`", response="` + response + `"`
+}
+
+func TestDigestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secrets := func(username, realm string) (string, bool) {
+		if username == "admin" {
+			return "s3cret", true
+		}
+		return "", false
+	}
+
+	// getChallenges performs an unauthenticated request and returns the challenges
+	getChallenges := func(t *testing.T, mw Middleware) []string {
+		c, w := TestContext("GET", "/admin?x=1", nil)
+		mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		return w.Header().Values("WWW-Authenticate")
+	}
+
+	t.Run("should send one challenge per algorithm", func(t *testing.T) {
+		challenges := getChallenges(t, DigestAuth(secrets, WithRealm("ops")))
+
+		require.Len(t, challenges, 2)
+		assert.Contains(t, challenges[0], "algorithm=SHA-256")
+		assert.Contains(t, challenges[1], "algorithm=MD5")
+		for _, ch := range challenges {
+			assert.Contains(t, ch, `realm="ops"`)
+			assert.Contains(t, ch, `qop="auth"`)
+		}
+	})
+
+	for _, alg := range []string{DigestSHA256, DigestMD5} {
+		t.Run("should authenticate valid response with "+alg, func(t *testing.T) {
+			mw := DigestAuth(secrets, WithDigestAlgorithms(alg), WithAuthRoles(func(string) []string {
+				return []string{"ops"}
+			}))
+			challenge := getChallenges(t, mw)[0]
+
+			c, w := TestContext("GET", "/admin?x=1", map[string]string{
+				"Authorization": digestAuthorization(challenge, "admin", "s3cret", "GET", "/admin?x=1"),
+			})
+			var userID string
+			var roles []string
+			mw(func(c *gin.Context) {
+				userID, _ = GetUserID(c)
+				roles, _ = GetUserRoles(c)
+				c.Status(http.StatusOK)
+			})(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "admin", userID)
+			assert.Equal(t, []string{"ops"}, roles)
+		})
+	}
+
+	t.Run("should reject wrong password", func(t *testing.T) {
+		mw := DigestAuth(secrets)
+		challenge := getChallenges(t, mw)[0]
+
+		c, w := TestContext("GET", "/admin?x=1", map[string]string{
+			"Authorization": digestAuthorization(challenge, "admin", "wrong", "GET", "/admin?x=1"),
+		})
+		mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid credentials")
+		assert.NotContains(t, w.Header().Get("WWW-Authenticate"), "stale")
+	})
+
+	t.Run("should reject unknown user", func(t *testing.T) {
+		mw := DigestAuth(secrets)
+		challenge := getChallenges(t, mw)[0]
+
+		c, w := TestContext("GET", "/admin?x=1", map[string]string{
+			"Authorization": digestAuthorization(challenge, "bob", "s3cret", "GET", "/admin?x=1"),
+		})
+		mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject response computed for another uri", func(t *testing.T) {
+		mw := DigestAuth(secrets)
+		challenge := getChallenges(t, mw)[0]
+
+		c, w := TestContext("GET", "/admin?x=1", map[string]string{
+			"Authorization": digestAuthorization(challenge, "admin", "s3cret", "GET", "/other"),
+		})
+		mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject replayed nonce counts", func(t *testing.T) {
+		mw := DigestAuth(secrets)
+		challenge := getChallenges(t, mw)[0]
+		serve := func(nc string) int {
+			c, w := TestContext("GET", "/admin?x=1", map[string]string{
+				"Authorization": digestAuthorizationCount(challenge, "admin", "s3cret", "GET", "/admin?x=1", nc),
+			})
+			mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, serve("00000001"))
+		assert.Equal(t, http.StatusUnauthorized, serve("00000001"))
+		assert.Equal(t, http.StatusOK, serve("00000003"))
+		assert.Equal(t, http.StatusUnauthorized, serve("00000002"))
+	})
+
+	t.Run("should answer stale when nonce slots are full", func(t *testing.T) {
+		mw := DigestAuth(secrets, WithDigestMaxNonces(1))
+		serve := func(challenge string) *httptest.ResponseRecorder {
+			c, w := TestContext("GET", "/admin?x=1", map[string]string{
+				"Authorization": digestAuthorization(challenge, "admin", "s3cret", "GET", "/admin?x=1"),
+			})
+			mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+			return w
+		}
+
+		assert.Equal(t, http.StatusOK, serve(getChallenges(t, mw)[0]).Code)
+		w := serve(getChallenges(t, mw)[0])
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "stale=true")
+	})
+
+	t.Run("should reject nonce issued by another instance", func(t *testing.T) {
+		challenge := getChallenges(t, DigestAuth(secrets))[0]
+
+		c, w := TestContext("GET", "/admin?x=1", map[string]string{
+			"Authorization": digestAuthorization(challenge, "admin", "s3cret", "GET", "/admin?x=1"),
+		})
+		DigestAuth(secrets)(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should mark expired nonce as stale", func(t *testing.T) {
+		mw := DigestAuth(secrets, WithDigestNonceExpiry(time.Millisecond))
+		challenge := getChallenges(t, mw)[0]
+		time.Sleep(5 * time.Millisecond)
+
+		c, w := TestContext("GET", "/admin?x=1", map[string]string{
+			"Authorization": digestAuthorization(challenge, "admin", "s3cret", "GET", "/admin?x=1"),
+		})
+		mw(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "stale=true")
+	})
+
+	t.Run("should panic on unsupported algorithm", func(t *testing.T) {
+		assert.Panics(t, func() {
+			DigestAuth(secrets, WithDigestAlgorithms("SHA-512"))
+		})
+	})
+}
+
+func TestParseAuthParams(t *testing.T) {
+	params := parseAuthParams(`username="Mufasa", realm="http-auth@example.org", nc=00000001, qop=auth, opaque="a\"b"`)
+
+	assert.Equal(t, "Mufasa", params["username"])
+	assert.Equal(t, "http-auth@example.org", params["realm"])
+	assert.Equal(t, "00000001", params["nc"])
+	assert.Equal(t, "auth", params["qop"])
+	assert.Equal(t, `a"b`, params["opaque"])
+}
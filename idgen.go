@@ -0,0 +1,204 @@
+package ginx
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	mrand "math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// ID Generators - for WithRequestIDGenerator
+// ============================================================================
+
+// UUIDv4 generates a random RFC 9562 version 4 UUID
+func UUIDv4() string {
+	var b [16]byte
+	randomBytes(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// UUIDv7 generates a time-ordered RFC 9562 version 7 UUID.
+// IDs from this process sort by creation time, including IDs of the same millisecond.
+func UUIDv7() string {
+	var b [16]byte
+	randomBytes(b[:])
+
+	uuidv7State.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms > uuidv7State.ms {
+		uuidv7State.ms = ms
+		uuidv7State.seq = uint16(b[6])<<8&0x700 | uint16(b[7]) // Random start, leaving room to count up
+	} else if uuidv7State.seq++; uuidv7State.seq > 0xfff {
+		uuidv7State.ms++ // Sequence exhausted, borrow the next millisecond
+		uuidv7State.seq = 0
+	}
+	ms, seq := uuidv7State.ms, uuidv7State.seq
+	uuidv7State.mu.Unlock()
+
+	b[0], b[1], b[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	b[3], b[4], b[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	b[6] = 0x70 | byte(seq>>8)
+	b[7] = byte(seq)
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+var uuidv7State struct {
+	mu  sync.Mutex
+	ms  int64
+	seq uint16
+}
+
+// ULID generates a lexicographically sortable ULID (26 Crockford base32 characters).
+// IDs of the same millisecond increment the random part, so they stay ordered.
+func ULID() string {
+	ulidState.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms > ulidState.ms || !incrementBytes(ulidState.entropy[:]) {
+		if ms <= ulidState.ms {
+			ms = ulidState.ms + 1 // Random part exhausted, borrow the next millisecond
+		}
+		ulidState.ms = ms
+		randomBytes(ulidState.entropy[:])
+		ulidState.entropy[0] &= 0x7f // Leave room to count up
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint16(b[0:2], uint16(ulidState.ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ulidState.ms))
+	copy(b[6:], ulidState.entropy[:])
+	ulidState.mu.Unlock()
+
+	return encodeULID(b)
+}
+
+var ulidState struct {
+	mu      sync.Mutex
+	ms      int64
+	entropy [10]byte
+}
+
+// ksuidEpoch is the KSUID epoch (2014-05-13T16:53:20Z)
+const ksuidEpoch = 1400000000
+
+// KSUID generates a K-Sortable Unique ID: 27 base62 characters, sortable by second
+func KSUID() string {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(time.Now().Unix()-ksuidEpoch))
+	randomBytes(b[4:])
+	return encodeBase62(b)
+}
+
+// snowflakeEpoch is the Snowflake epoch (2020-01-01T00:00:00Z) in milliseconds
+const snowflakeEpoch = 1577836800000
+
+// NewSnowflakeGenerator creates a Snowflake-style generator for the node (0-1023).
+// IDs are decimal 63-bit numbers made of 41 bits of milliseconds since 2020, the node ID
+// and a 12 bit sequence, so they are unique across nodes and sort by time.
+func NewSnowflakeGenerator(nodeID int64) (func() string, error) {
+	if nodeID < 0 || nodeID > 1023 {
+		return nil, errors.New("ginx: snowflake node ID must be between 0 and 1023")
+	}
+
+	var (
+		mu  sync.Mutex
+		ms  int64
+		seq int64
+	)
+	return func() string {
+		mu.Lock()
+		now := time.Now().UnixMilli() - snowflakeEpoch
+		if now > ms {
+			ms, seq = now, 0
+		} else if seq++; seq > 0xfff {
+			ms++ // Sequence exhausted, borrow the next millisecond
+			seq = 0
+		}
+		id := ms<<22 | nodeID<<12 | seq
+		mu.Unlock()
+		return strconv.FormatInt(id, 10)
+	}, nil
+}
+
+// ============================================================================
+// ID Generator Helpers
+// ============================================================================
+
+// randomBytes fills b from crypto/rand, falling back to the runtime's
+// per-process random source so IDs stay unique when entropy fails
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err == nil {
+		return
+	}
+	for i := 0; i < len(b); i += 8 {
+		var chunk [8]byte
+		binary.LittleEndian.PutUint64(chunk[:], mrand.Uint64())
+		copy(b[i:], chunk[:])
+	}
+}
+
+// incrementBytes adds one to a big-endian number, reporting false on overflow
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatUUID formats 16 bytes as 8-4-4-4-12 lowercase hex
+func formatUUID(b [16]byte) string {
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// encodeULID encodes 128 bits as 26 uppercase Crockford base32 characters
+func encodeULID(b [16]byte) string {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = alphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// encodeBase62 encodes 160 bits as 27 base62 characters, zero padded
+func encodeBase62(b [20]byte) string {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// Big-endian base 2^32 digits, divided by 62 repeatedly
+	var parts [5]uint32
+	for i := range parts {
+		parts[i] = binary.BigEndian.Uint32(b[i*4:])
+	}
+	var out [27]byte
+	for i := 26; i >= 0; i-- {
+		var rem uint64
+		for j := range parts {
+			value := rem<<32 | uint64(parts[j])
+			parts[j] = uint32(value / 62)
+			rem = value % 62
+		}
+		out[i] = alphabet[rem]
+	}
+	return string(out[:])
+}
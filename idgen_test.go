@@ -0,0 +1,99 @@
+package ginx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDGenerators(t *testing.T) {
+	t.Run("UUIDv4", func(t *testing.T) {
+		id := UUIDv4()
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+		assert.True(t, UUIDValidator(id))
+	})
+
+	t.Run("UUIDv7 embeds the time and sorts", func(t *testing.T) {
+		before := time.Now().UnixMilli()
+		id := UUIDv7()
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+
+		ms, err := strconv.ParseInt(id[0:8]+id[9:13], 16, 64)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, ms, before)
+		assert.LessOrEqual(t, ms, time.Now().UnixMilli())
+
+		assertSortedUnique(t, UUIDv7)
+	})
+
+	t.Run("ULID embeds the time and sorts", func(t *testing.T) {
+		before := time.Now().UnixMilli()
+		id := ULID()
+		assert.Regexp(t, `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`, id)
+		assert.True(t, ULIDValidator(id))
+
+		var ms int64
+		for _, ch := range id[:10] {
+			ms = ms<<5 | int64(strings.IndexRune("0123456789ABCDEFGHJKMNPQRSTVWXYZ", ch))
+		}
+		assert.GreaterOrEqual(t, ms, before)
+		assert.LessOrEqual(t, ms, time.Now().UnixMilli())
+
+		assertSortedUnique(t, ULID)
+	})
+
+	t.Run("KSUID", func(t *testing.T) {
+		id := KSUID()
+		assert.Regexp(t, `^[0-9A-Za-z]{27}$`, id)
+		assert.NotEqual(t, id, KSUID())
+		assert.Equal(t, "000000000000000000000000000", encodeBase62([20]byte{}))
+		assert.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", encodeBase62([20]byte{
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		}))
+	})
+
+	t.Run("Snowflake", func(t *testing.T) {
+		_, err := NewSnowflakeGenerator(1024)
+		assert.Error(t, err)
+
+		next, err := NewSnowflakeGenerator(42)
+		require.NoError(t, err)
+		id, err := strconv.ParseInt(next(), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), id>>12&0x3ff)
+
+		var prev int64
+		for i := 0; i < 10000; i++ {
+			id, err := strconv.ParseInt(next(), 10, 64)
+			require.NoError(t, err)
+			require.Greater(t, id, prev)
+			prev = id
+		}
+	})
+
+	t.Run("usable as request ID generators", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+		RequestID(WithRequestIDGenerator(UUIDv7))(func(*gin.Context) {})(c)
+		assert.True(t, UUIDValidator(w.Header().Get("X-Request-ID")))
+	})
+}
+
+// assertSortedUnique checks that IDs generated in a burst are unique and in generation order
+func assertSortedUnique(t *testing.T, generate func() string) {
+	t.Helper()
+	ids := make([]string, 10000)
+	seen := make(map[string]bool, len(ids))
+	for i := range ids {
+		ids[i] = generate()
+		require.False(t, seen[ids[i]], "duplicate id %s", ids[i])
+		seen[ids[i]] = true
+	}
+	assert.True(t, sort.StringsAreSorted(ids))
+}
@@ -1,18 +1,172 @@
 package ginx
 
 import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/simp-lee/logger"
 )
 
+// ============================================================================
+// Logger - Structured Access Logs
+// ============================================================================
+
+// redactedValue replaces redacted query parameters and headers
+const redactedValue = "[REDACTED]"
+
+// defaultLogFields are the access log fields of Logger(), in output order.
+// Correlation fields (request_id, trace_id, span_id, tenant_id) are only logged when set.
+var defaultLogFields = []string{
+	"method", "path", "query", "status", "latency", "ip", "user_agent", "size", "protocol", "referer",
+	"request_id", "trace_id", "span_id", "tenant_id",
+}
+
+// LoggerConfig access log configuration
+type LoggerConfig struct {
+	Logger        *slog.Logger             // Destination, default created by logger.New(LoggerOptions...)
+	LoggerOptions []logger.Option          // Options of the default destination
+	Fields        []string                 // Standard fields to log, default all but user_id
+	CustomFields  func(*gin.Context) []any // Extra key-value pairs appended to every line, optional
+	Route         bool                     // Log the route template (c.FullPath()) as path
+	Headers       []string                 // Request headers logged in the "headers" group, optional
+	RedactQuery   []string                 // Query parameters whose values are redacted (case-insensitive)
+	RedactHeaders []string                 // Headers whose values are redacted (case-insensitive)
+	RedactPattern *regexp.Regexp           // Query parameters and headers whose name matches are redacted, optional
+
+	LogBodies        bool      // Capture request and response bodies, disabled by default
+	BodyWhen         Condition // Logs captured bodies only when true, evaluated after the handler; nil logs all
+	BodyLimit        int       // Bytes captured per direction, default 4KB
+	BodyContentTypes []string  // Media types captured, "text/*" matches a whole type
+	RedactBodyFields []string  // JSON and form fields whose values are redacted (case-insensitive)
+
+	Skip          Condition                 // Drops the line when true, evaluated after the handler, optional
+	SampleRate    int                       // Keeps 1 in SampleRate successful requests, default 1 (all)
+	SlowThreshold time.Duration             // Latency above which the level is raised, 0 disables
+	WarnRPS       int                       // Warning lines per second per key, 0 disables the limit
+	WarnBurst     int                       // Warning burst per key
+	WarnKey       func(*gin.Context) string // Warning limit key, default method, route and status
+}
+
+// defaultLoggerConfig returns default access log configuration, matching Logger()
+func defaultLoggerConfig() *LoggerConfig {
+	return &LoggerConfig{
+		Fields: defaultLogFields,
+
+		BodyLimit:        4 << 10,
+		BodyContentTypes: []string{"application/json", "application/xml", "application/x-www-form-urlencoded", "text/*"},
+		RedactBodyFields: []string{"password", "token", "access_token", "refresh_token", "client_secret", "api_key"},
+	}
+}
+
+// WithLoggerOptions configures the default destination, see github.com/simp-lee/logger
+func WithLoggerOptions(options ...logger.Option) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.LoggerOptions = append(c.LoggerOptions, options...)
+	}
+}
+
+// WithAccessLogger writes access logs to an existing logger instead of creating one
+func WithAccessLogger(log *slog.Logger) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.Logger = log
+	}
+}
+
+// WithLogFields selects the standard fields to log, e.g. WithLogFields("method", "path", "status", "latency").
+// Available: method, path, query, status, latency, ip, user_agent, size, protocol, referer,
+// request_id, trace_id, span_id, tenant_id, user_id.
+func WithLogFields(fields ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.Fields = fields
+	}
+}
+
+// WithLogCustomFields appends the key-value pairs returned by fn to every line
+func WithLogCustomFields(fn func(*gin.Context) []any) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.CustomFields = fn
+	}
+}
+
+// WithLogRoute logs the route template ("/users/:id") as path instead of the raw path.
+// Unmatched requests keep the raw path.
+func WithLogRoute() Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.Route = true
+	}
+}
+
+// WithLogHeaders logs the given request headers in a "headers" group
+func WithLogHeaders(names ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.Headers = append(c.Headers, names...)
+	}
+}
+
+// WithDefaultRedaction redacts the credentials read by the auth middlewares: the token,
+// access_token and api_key query parameters (also in referer) and the Authorization,
+// Proxy-Authorization, Cookie and X-API-Key headers
+func WithDefaultRedaction() Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.RedactQuery = append(c.RedactQuery, "token", "access_token", "api_key")
+		c.RedactHeaders = append(c.RedactHeaders, "Authorization", "Proxy-Authorization", "Cookie", "X-API-Key")
+	}
+}
+
+// WithLogRedactQuery redacts the values of the given query parameters (also in referer)
+func WithLogRedactQuery(names ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.RedactQuery = append(c.RedactQuery, names...)
+	}
+}
+
+// WithLogRedactHeaders redacts the values of the given headers
+func WithLogRedactHeaders(names ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.RedactHeaders = append(c.RedactHeaders, names...)
+	}
+}
+
+// WithLogRedactPattern redacts query parameters and headers whose name matches pattern,
+// e.g. regexp.MustCompile(`(?i)secret|password`)
+func WithLogRedactPattern(pattern *regexp.Regexp) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.RedactPattern = pattern
+	}
+}
+
 // Logger create a logging middleware with the given options.
 func Logger(options ...logger.Option) Middleware {
+	return LoggerWith(WithLoggerOptions(options...))
+}
+
+// LoggerWith create a logging middleware with field selection, custom fields, redaction,
+// body capture, skip rules and sampling.
+func LoggerWith(options ...Option[LoggerConfig]) Middleware {
+	config := defaultLoggerConfig()
+	for _, opt := range options {
+		opt(config)
+	}
+
 	// Initialize the logger
-	log, err := logger.New(options...)
-	if err != nil {
-		panic("failed to create logger: " + err.Error())
+	log := config.Logger
+	if log == nil {
+		l, err := logger.New(config.LoggerOptions...)
+		if err != nil {
+			panic("failed to create logger: " + err.Error())
+		}
+		log = l.Logger
+	}
+	redactor := newLogRedactor(config)
+	bodyRedactor := newBodyRedactor(config.RedactBodyFields)
+	sampler := newLogSampler(config)
+	fields := make(map[string]bool, len(config.Fields))
+	for _, field := range config.Fields {
+		fields[field] = true
 	}
 
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
@@ -20,50 +174,188 @@ func Logger(options ...logger.Option) Middleware {
 			// Start timer
 			start := time.Now()
 
+			var bodies *bodyCapture
+			if config.LogBodies {
+				bodies = captureBodies(c, config)
+			}
+
 			// Process request
 			next(c)
+			if bodies != nil {
+				bodies.restore(c)
+			}
 
-			// Log request details
-			path := c.Request.URL.Path
+			// Decide whether and at which level to log
 			latency := time.Since(start)
 			status := c.Writer.Status()
-
-			// Prepare log fields
-			fields := []any{
-				"method", c.Request.Method,
-				"path", path,
-				"query", c.Request.URL.RawQuery,
-				"status", status,
-				"latency", latency,
-				"ip", c.ClientIP(),
-				"user_agent", c.Request.UserAgent(),
-				"size", c.Writer.Size(),
-				"protocol", c.Request.Proto,
-				"referer", c.Request.Referer(),
+			if config.Skip != nil && config.Skip(c) {
+				return
 			}
-
-			if rid, ok := GetRequestID(c); ok && rid != "" {
-				fields = append(fields, "request_id", rid)
+			slow := config.SlowThreshold > 0 && latency > config.SlowThreshold
+			if !sampler.keep(c, status, slow) {
+				return
 			}
-
-			// Log based on status code
+			level := slog.LevelInfo
 			switch {
 			case status >= 500:
-				log.Error("HTTP Request", fields...)
+				level = slog.LevelError
 			case status >= 400:
-				log.Warn("HTTP Request", fields...)
-			default:
-				log.Info("HTTP Request", fields...)
+				level = slog.LevelWarn
+			}
+			if slow && level < slog.LevelError {
+				level += 4 // Info to Warn, Warn to Error
+			}
+
+			// Log request details
+			path := c.Request.URL.Path
+			if route := c.FullPath(); config.Route && route != "" {
+				path = route
 			}
 
+			// Prepare log fields
+			var attrs []any
+			add := func(key string, value any) {
+				if fields[key] {
+					attrs = append(attrs, key, value)
+				}
+			}
+			add("method", c.Request.Method)
+			add("path", path)
+			add("query", redactor.query(c.Request.URL.RawQuery))
+			add("status", status)
+			add("latency", latency)
+			add("ip", c.ClientIP())
+			add("user_agent", c.Request.UserAgent())
+			add("size", c.Writer.Size())
+			add("protocol", c.Request.Proto)
+			add("referer", redactor.url(c.Request.Referer()))
+			correlation := correlationFields(c, fields)
+			attrs = append(attrs, correlation...)
+
+			if len(config.Headers) > 0 {
+				var headers []any
+				for _, name := range config.Headers {
+					if values := c.Request.Header.Values(name); len(values) > 0 {
+						headers = append(headers, strings.ToLower(name), redactor.header(name, strings.Join(values, ", ")))
+					}
+				}
+				if len(headers) > 0 {
+					attrs = append(attrs, slog.Group("headers", headers...))
+				}
+			}
+			if bodies != nil && (config.BodyWhen == nil || config.BodyWhen(c)) {
+				attrs = append(attrs, bodies.fields(c, bodyRedactor)...)
+			}
+			if slow {
+				attrs = append(attrs, "slow", true)
+			}
+			if config.CustomFields != nil {
+				attrs = append(attrs, config.CustomFields(c)...)
+			}
+
+			// Log based on status code and latency
+			log.Log(c.Request.Context(), level, "HTTP Request", attrs...)
+
 			// Log errors if any
 			if len(c.Errors) > 0 {
-				errFields := []any{"path", path, "errors", c.Errors.String()}
-				if rid, ok := GetRequestID(c); ok && rid != "" {
-					errFields = append(errFields, "request_id", rid)
-				}
+				errFields := append([]any{"path", path, "errors", c.Errors.String()}, correlation...)
 				log.Error("Request errors", errFields...)
 			}
 		}
 	}
 }
+
+// correlationFields returns the selected request, trace, tenant and user IDs that are set
+func correlationFields(c *gin.Context, fields map[string]bool) []any {
+	var attrs []any
+	if rid, ok := GetRequestID(c); ok && rid != "" && fields["request_id"] {
+		attrs = append(attrs, "request_id", rid)
+	}
+	if tc, ok := GetTraceContext(c); ok {
+		if fields["trace_id"] {
+			attrs = append(attrs, "trace_id", tc.TraceID)
+		}
+		if fields["span_id"] {
+			attrs = append(attrs, "span_id", tc.SpanID)
+		}
+	}
+	if tenantID, ok := GetTenantID(c); ok && tenantID != "" && fields["tenant_id"] {
+		attrs = append(attrs, "tenant_id", tenantID)
+	}
+	if userID, ok := GetUserID(c); ok && userID != "" && fields["user_id"] {
+		attrs = append(attrs, "user_id", userID)
+	}
+	return attrs
+}
+
+// logRedactor hides secret query parameters and headers
+type logRedactor struct {
+	params  map[string]bool
+	headers map[string]bool
+	pattern *regexp.Regexp
+}
+
+// newLogRedactor indexes the redacted names in lower case
+func newLogRedactor(config *LoggerConfig) *logRedactor {
+	r := &logRedactor{
+		params:  make(map[string]bool, len(config.RedactQuery)),
+		headers: make(map[string]bool, len(config.RedactHeaders)),
+		pattern: config.RedactPattern,
+	}
+	for _, name := range config.RedactQuery {
+		r.params[strings.ToLower(name)] = true
+	}
+	for _, name := range config.RedactHeaders {
+		r.headers[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+// redacts reports whether a name is listed or matches the pattern
+func (r *logRedactor) redacts(names map[string]bool, name string) bool {
+	return names[strings.ToLower(name)] || (r.pattern != nil && r.pattern.MatchString(name))
+}
+
+// query redacts parameter values in a raw query
+func (r *logRedactor) query(raw string) string {
+	if len(r.params) == 0 && r.pattern == nil {
+		return raw
+	}
+	return redactPairs(raw, func(name string) bool { return r.redacts(r.params, name) })
+}
+
+// url redacts the query of a URL such as the referer
+func (r *logRedactor) url(raw string) string {
+	base, query, ok := strings.Cut(raw, "?")
+	if !ok {
+		return raw
+	}
+	return base + "?" + r.query(query)
+}
+
+// header returns the value of a header, or the redaction marker
+func (r *logRedactor) header(name, value string) string {
+	if r.redacts(r.headers, name) {
+		return redactedValue
+	}
+	return value
+}
+
+// redactPairs replaces the values of matching keys in an URL-encoded string, keeping order and encoding
+func redactPairs(raw string, redacts func(name string) bool) string {
+	if raw == "" {
+		return raw
+	}
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		key, _, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if hasValue && redacts(name) {
+			pairs[i] = key + "=" + redactedValue
+		}
+	}
+	return strings.Join(pairs, "&")
+}
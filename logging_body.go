@@ -0,0 +1,211 @@
+package ginx
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Logger - Request and Response Body Capture
+// ============================================================================
+
+// WithLogBodies captures request and response bodies, logged as request_body and response_body
+// when the condition holds. The condition runs after the handler, so it can check the status,
+// e.g. WithLogBodies(Or(PathHasPrefix("/partner"), StatusAtLeast(400))); nil logs every request.
+func WithLogBodies(when Condition) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.LogBodies = true
+		c.BodyWhen = when
+	}
+}
+
+// WithLogBodyLimit sets the bytes captured per direction, longer bodies are truncated
+func WithLogBodyLimit(limit int) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.BodyLimit = limit
+	}
+}
+
+// WithLogBodyContentTypes sets the media types whose bodies are captured, e.g. "application/json", "text/*"
+func WithLogBodyContentTypes(types ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.BodyContentTypes = types
+	}
+}
+
+// WithLogRedactBodyFields redacts the given JSON and form fields, in addition to the defaults
+func WithLogRedactBodyFields(names ...string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.RedactBodyFields = append(c.RedactBodyFields, names...)
+	}
+}
+
+// bodyCapture holds the captured bodies of one request
+type bodyCapture struct {
+	config   *LoggerConfig
+	request  *bodyBuffer
+	writer   gin.ResponseWriter
+	response *bodyWriter
+}
+
+// captureBodies reads the start of the request body, restoring it for handlers,
+// and wraps the writer to capture the start of the response body
+func captureBodies(c *gin.Context, config *LoggerConfig) *bodyCapture {
+	bodies := &bodyCapture{config: config, writer: c.Writer}
+
+	if body := c.Request.Body; body != nil && body != http.NoBody && bodies.allowed(c.GetHeader("Content-Type")) {
+		head, err := io.ReadAll(io.LimitReader(body, int64(config.BodyLimit)+1))
+		bodies.request = &bodyBuffer{limit: config.BodyLimit}
+		bodies.request.Write(head)
+		c.Request.Body = &replayBody{Reader: io.MultiReader(bytes.NewReader(head), errReader{err}, body), Closer: body}
+	}
+
+	bodies.response = &bodyWriter{ResponseWriter: c.Writer, buf: bodyBuffer{limit: config.BodyLimit}, allowed: bodies.allowed}
+	c.Writer = bodies.response
+	return bodies
+}
+
+// restore puts back the original response writer
+func (b *bodyCapture) restore(c *gin.Context) {
+	c.Writer = b.writer
+}
+
+// fields returns the captured bodies as log fields, redacted by content type
+func (b *bodyCapture) fields(c *gin.Context, redactor *bodyRedactor) []any {
+	var attrs []any
+	if b.request != nil && len(b.request.data) > 0 {
+		attrs = append(attrs, "request_body", redactor.redact(c.GetHeader("Content-Type"), b.request.data))
+		if b.request.truncated {
+			attrs = append(attrs, "request_body_truncated", true)
+		}
+	}
+	contentType := b.writer.Header().Get("Content-Type")
+	if resp := &b.response.buf; len(resp.data) > 0 && b.response.capture {
+		attrs = append(attrs, "response_body", redactor.redact(contentType, resp.data))
+		if resp.truncated {
+			attrs = append(attrs, "response_body_truncated", true)
+		}
+	}
+	return attrs
+}
+
+// allowed reports whether the content type is in the allow-list
+func (b *bodyCapture) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range b.config.BodyContentTypes {
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyBuffer keeps the first limit bytes written to it
+type bodyBuffer struct {
+	data      []byte
+	limit     int
+	truncated bool
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.data); room < len(p) {
+		b.data = append(b.data, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.data = append(b.data, p...)
+	}
+	return len(p), nil
+}
+
+// replayBody serves the captured head of the request body followed by the rest
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader returns the error hit while reading the head of the request body
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+// bodyWriter is a gin response writer that captures the start of the response body.
+// The content type is checked on the first write; other responses are not buffered.
+type bodyWriter struct {
+	gin.ResponseWriter
+	buf     bodyBuffer
+	allowed func(contentType string) bool
+	decided bool
+	capture bool
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.capture = w.allowed(w.Header().Get("Content-Type"))
+	}
+	n, err := w.ResponseWriter.Write(data)
+	if w.capture && !w.buf.truncated {
+		w.buf.Write(data[:n])
+	}
+	return n, err
+}
+
+func (w *bodyWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// bodyRedactor hides secret fields in JSON and form bodies
+type bodyRedactor struct {
+	fields map[string]bool
+	json   *regexp.Regexp
+}
+
+// newBodyRedactor compiles a pattern matching the JSON members to redact, at any depth.
+// Matching the text rather than decoding also redacts truncated bodies.
+func newBodyRedactor(names []string) *bodyRedactor {
+	r := &bodyRedactor{fields: make(map[string]bool, len(names))}
+	if len(names) == 0 {
+		return r
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		r.fields[strings.ToLower(name)] = true
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	r.json = regexp.MustCompile(`("(?i:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	return r
+}
+
+// redact returns the body as a string with secret field values replaced
+func (r *bodyRedactor) redact(contentType string, body []byte) string {
+	if len(r.fields) == 0 {
+		return string(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return r.json.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+	case mediaType == "application/x-www-form-urlencoded":
+		return redactPairs(string(body), func(name string) bool { return r.fields[strings.ToLower(name)] })
+	}
+	return string(body)
+}
@@ -0,0 +1,133 @@
+package ginx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoggingBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(middleware Middleware, req *http.Request, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.Use(NewChain().Use(middleware).Build())
+		r.POST("/partner/orders", handler)
+		r.POST("/internal/orders", handler)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	}
+
+	t.Run("Captures and restores bodies with JSON redaction", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(WithAccessLogger(log), WithLogBodies(PathHasPrefix("/partner")))
+		payload := `{"order":1,"auth":{"password":"hunter2","token": 42},"items":["a"]}`
+		req := httptest.NewRequest("POST", "/partner/orders", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		w := serve(middleware, req, echo)
+
+		if w.Body.String() != payload {
+			t.Errorf("Handler should read the full body, got %q", w.Body.String())
+		}
+		record := last()
+		want := `{"order":1,"auth":{"password":"[REDACTED]","token": "[REDACTED]"},"items":["a"]}`
+		if record["request_body"] != want {
+			t.Errorf("Expected request_body %q, got %v", want, record["request_body"])
+		}
+		if record["response_body"] != want {
+			t.Errorf("Expected response_body %q, got %v", want, record["response_body"])
+		}
+	})
+
+	t.Run("Truncates at the limit and still redacts", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(WithAccessLogger(log), WithLogBodies(nil), WithLogBodyLimit(24))
+		payload := `{"id":1,"password":"abcdefghijklmnop"}`
+		req := httptest.NewRequest("POST", "/partner/orders", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := serve(middleware, req, echo)
+
+		if w.Body.String() != payload {
+			t.Errorf("Handler should read the full body, got %q", w.Body.String())
+		}
+		record := last()
+		if want := `{"id":1,"password":"[REDACTED]"`; record["request_body"] != want {
+			t.Errorf("Expected request_body %q, got %v", want, record["request_body"])
+		}
+		if record["request_body_truncated"] != true || record["response_body_truncated"] != true {
+			t.Error("Bodies over the limit should be marked truncated")
+		}
+	})
+
+	t.Run("Skips disallowed content types and unmatched conditions", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(WithAccessLogger(log), WithLogBodies(Or(PathHasPrefix("/partner"), StatusAtLeast(400))))
+		binary := func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/octet-stream", []byte{0, 1, 2})
+		}
+
+		req := httptest.NewRequest("POST", "/partner/orders", strings.NewReader("raw"))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		serve(middleware, req, binary)
+		record := last()
+		if _, ok := record["request_body"]; ok {
+			t.Error("Disallowed request content type should not be logged")
+		}
+		if _, ok := record["response_body"]; ok {
+			t.Error("Disallowed response content type should not be logged")
+		}
+
+		req = httptest.NewRequest("POST", "/internal/orders", strings.NewReader(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		serve(middleware, req, echo)
+		if _, ok := last()["request_body"]; ok {
+			t.Error("Bodies should only be logged when the condition holds")
+		}
+
+		req = httptest.NewRequest("POST", "/internal/orders", strings.NewReader("password=x&id=1"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		serve(middleware, req, func(c *gin.Context) {
+			c.String(http.StatusBadRequest, "invalid order")
+		})
+		record = last()
+		if record["request_body"] != "password=[REDACTED]&id=1" {
+			t.Errorf("Expected redacted form body, got %v", record["request_body"])
+		}
+		if record["response_body"] != "invalid order" {
+			t.Errorf("Expected text response body, got %v", record["response_body"])
+		}
+	})
+	t.Run("Does not buffer disallowed response types", func(t *testing.T) {
+		log, _ := captureLog(t)
+		middleware := LoggerWith(WithAccessLogger(log), WithLogBodies(nil))
+		var writer *bodyWriter
+		req := httptest.NewRequest("POST", "/partner/orders", nil)
+
+		w := serve(middleware, req, func(c *gin.Context) {
+			writer, _ = c.Writer.(*bodyWriter)
+			c.Data(http.StatusOK, "application/octet-stream", []byte{0x00, 0x01, 0x02})
+			c.Writer.Write([]byte{0x03})
+		})
+
+		if w.Body.Len() != 4 {
+			t.Errorf("Expected the full response to be written, got %d bytes", w.Body.Len())
+		}
+		if writer == nil {
+			t.Fatal("Expected the response writer to be wrapped")
+		}
+		if len(writer.buf.data) != 0 {
+			t.Errorf("Disallowed response should not be buffered, got %d bytes", len(writer.buf.data))
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package ginx
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// ============================================================================
+// Logger - Skip Rules and Sampling
+// ============================================================================
+
+// WithLogSkip drops the lines of requests matching the condition, e.g. WithLogSkip(PathIs("/health")).
+// The condition runs after the handler; combine with Not(StatusAtLeast(500)) to keep failures.
+func WithLogSkip(cond Condition) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.Skip = cond
+	}
+}
+
+// WithLogSampling keeps 1 in n successful requests. Errors, 4xx (see WithLogWarnLimit),
+// requests with gin errors and slow requests are always kept.
+func WithLogSampling(n int) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.SampleRate = n
+	}
+}
+
+// WithSlowThreshold raises the level of requests slower than threshold by one step
+// (Info to Warn, Warn to Error) and marks them with slow=true
+func WithSlowThreshold(threshold time.Duration) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.SlowThreshold = threshold
+	}
+}
+
+// WithLogWarnLimit limits 4xx lines to rps per second with burst per key, dropping the excess.
+// keyFunc defaults to method, route and status, e.g. "GET /users/:id 404".
+func WithLogWarnLimit(rps, burst int, keyFunc func(*gin.Context) string) Option[LoggerConfig] {
+	return func(c *LoggerConfig) {
+		c.WarnRPS = rps
+		c.WarnBurst = burst
+		c.WarnKey = keyFunc
+	}
+}
+
+// warnLimiterIdle is how long an unused warning limiter is kept
+const warnLimiterIdle = 5 * time.Minute
+
+// warnLimiter is a warning limiter with its last use
+type warnLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// logSampler decides which requests are logged
+type logSampler struct {
+	rate    uint64
+	count   atomic.Uint64
+	rps     int
+	burst   int
+	keyFunc func(*gin.Context) string
+
+	mu        sync.Mutex
+	limiters  map[string]*warnLimiter
+	lastSweep time.Time
+}
+
+// newLogSampler creates the sampler. Idle warning limiters are evicted lazily,
+// so the sampler needs no background goroutine.
+func newLogSampler(config *LoggerConfig) *logSampler {
+	s := &logSampler{rate: 1, rps: config.WarnRPS, burst: max(config.WarnBurst, 1), keyFunc: config.WarnKey}
+	if config.SampleRate > 1 {
+		s.rate = uint64(config.SampleRate)
+	}
+	if s.rps > 0 {
+		s.limiters = make(map[string]*warnLimiter)
+		s.lastSweep = time.Now()
+		if s.keyFunc == nil {
+			s.keyFunc = defaultWarnKey
+		}
+	}
+	return s
+}
+
+// keep reports whether the request should be logged
+func (s *logSampler) keep(c *gin.Context, status int, slow bool) bool {
+	switch {
+	case status >= 500 || len(c.Errors) > 0 || slow:
+		return true
+	case status >= 400:
+		return s.limiters == nil || s.limiter(s.keyFunc(c)).Allow()
+	default:
+		return (s.count.Add(1)-1)%s.rate == 0
+	}
+}
+
+// limiter returns the warning limiter of a key, creating it on first use
+func (s *logSampler) limiter(key string) *rate.Limiter {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= warnLimiterIdle {
+		for k, l := range s.limiters {
+			if now.Sub(l.lastSeen) >= warnLimiterIdle {
+				delete(s.limiters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	l, ok := s.limiters[key]
+	if !ok {
+		l = &warnLimiter{limiter: rate.NewLimiter(rate.Limit(s.rps), s.burst)}
+		s.limiters[key] = l
+	}
+	l.lastSeen = now
+	return l.limiter
+}
+
+// defaultWarnKey groups warnings by method, route template and status
+func defaultWarnKey(c *gin.Context) string {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	return c.Request.Method + " " + route + " " + strconv.Itoa(c.Writer.Status())
+}
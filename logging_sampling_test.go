@@ -0,0 +1,164 @@
+package ginx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoggingSampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(options ...Option[LoggerConfig]) (*gin.Engine, func() []map[string]any) {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewJSONHandler(&buf, nil))
+		r := gin.New()
+		r.Use(NewChain().Use(LoggerWith(append([]Option[LoggerConfig]{WithAccessLogger(log)}, options...)...)).Build())
+		r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+		r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+		r.GET("/slow", func(c *gin.Context) {
+			time.Sleep(20 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+		lines := func() []map[string]any {
+			var records []map[string]any
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				var record map[string]any
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("Failed to decode log line %q: %v", line, err)
+				}
+				records = append(records, record)
+			}
+			return records
+		}
+		return r, lines
+	}
+	serve := func(r *gin.Engine, path string, times int) {
+		for range times {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+	}
+
+	t.Run("Skip drops matching requests", func(t *testing.T) {
+		r, lines := setup(WithLogSkip(And(PathIs("/health"), Not(StatusAtLeast(500)))))
+
+		serve(r, "/health", 3)
+		serve(r, "/ok", 1)
+
+		records := lines()
+		if len(records) != 1 || records[0]["path"] != "/ok" {
+			t.Errorf("Expected only the /ok line, got %v", records)
+		}
+	})
+
+	t.Run("Sampling keeps 1 in N successes and all errors", func(t *testing.T) {
+		r, lines := setup(WithLogSampling(5))
+
+		serve(r, "/ok", 10)
+		serve(r, "/fail", 3)
+		serve(r, "/missing", 3)
+
+		counts := map[string]int{}
+		for _, record := range lines() {
+			counts[record["path"].(string)]++
+		}
+		if counts["/ok"] != 2 {
+			t.Errorf("Expected 2 sampled successes, got %d", counts["/ok"])
+		}
+		if counts["/fail"] != 3 || counts["/missing"] != 3 {
+			t.Errorf("Errors and warnings should not be sampled, got %v", counts)
+		}
+	})
+
+	t.Run("Slow requests are kept at a raised level", func(t *testing.T) {
+		r, lines := setup(WithLogSampling(1000), WithSlowThreshold(5*time.Millisecond))
+
+		serve(r, "/ok", 1)
+		serve(r, "/slow", 2)
+
+		records := lines()
+		if len(records) != 3 {
+			t.Fatalf("Expected the first and all slow requests, got %d lines", len(records))
+		}
+		if records[0]["level"] != "INFO" || records[0]["slow"] != nil {
+			t.Errorf("Fast request should stay at INFO, got %v", records[0])
+		}
+		for _, record := range records[1:] {
+			if record["level"] != "WARN" || record["slow"] != true {
+				t.Errorf("Slow request should be logged at WARN with slow=true, got %v", record)
+			}
+		}
+	})
+
+	t.Run("Warnings are rate limited per key", func(t *testing.T) {
+		r, lines := setup(WithLogWarnLimit(1, 2, nil))
+		r.GET("/gone", func(c *gin.Context) { c.Status(http.StatusGone) })
+
+		serve(r, "/missing", 5)
+		serve(r, "/gone", 5)
+		serve(r, "/fail", 3)
+
+		counts := map[string]int{}
+		for _, record := range lines() {
+			counts[record["path"].(string)]++
+		}
+		if counts["/missing"] != 2 || counts["/gone"] != 2 {
+			t.Errorf("Expected the burst of warnings per key, got %v", counts)
+		}
+		if counts["/fail"] != 3 {
+			t.Errorf("Errors should not be rate limited, got %d", counts["/fail"])
+		}
+	})
+}
+
+func TestLogSamplerWarnLimiters(t *testing.T) {
+	t.Run("Concurrent first use shares one limiter", func(t *testing.T) {
+		s := newLogSampler(&LoggerConfig{WarnRPS: 1, WarnBurst: 2})
+		var allowed atomic.Int32
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if s.limiter("GET /missing 404").Allow() {
+					allowed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := allowed.Load(); got != 2 {
+			t.Errorf("Expected only the burst of 2 to pass, got %d", got)
+		}
+	})
+
+	t.Run("Idle limiters are evicted", func(t *testing.T) {
+		s := newLogSampler(&LoggerConfig{WarnRPS: 1, WarnBurst: 1})
+		s.limiter("idle")
+		s.limiters["idle"].lastSeen = time.Now().Add(-warnLimiterIdle)
+		s.lastSweep = time.Now().Add(-warnLimiterIdle)
+
+		s.limiter("active")
+
+		if _, ok := s.limiters["idle"]; ok {
+			t.Error("Idle limiter should be evicted")
+		}
+		if _, ok := s.limiters["active"]; !ok {
+			t.Error("Active limiter should be kept")
+		}
+	})
+}
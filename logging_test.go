@@ -1,9 +1,13 @@
 package ginx
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -565,3 +569,147 @@ func TestLoggingLoggerOptions(t *testing.T) {
 		}
 	})
 }
+
+// captureLog returns a logger writing JSON lines and a function decoding the last line
+func captureLog(t *testing.T) (*slog.Logger, func() map[string]any) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+	return log, func() map[string]any {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var record map[string]any
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", buf.String(), err)
+		}
+		return record
+	}
+}
+
+func TestLoggingFieldCustomisation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(req *http.Request, middlewares ...Middleware) {
+		chain := NewChain()
+		for _, m := range middlewares {
+			chain.Use(m)
+		}
+		r := gin.New()
+		r.Use(chain.Build())
+		r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("Selected fields and route template", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(
+			WithAccessLogger(log),
+			WithLogFields("method", "path", "status", "user_id"),
+			WithLogRoute(),
+			WithLogCustomFields(func(c *gin.Context) []any {
+				return []any{"user_id_param", c.Param("id")}
+			}),
+		)
+		auth := func(next gin.HandlerFunc) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				SetUserID(c, "user123")
+				next(c)
+			}
+		}
+
+		serve(httptest.NewRequest("GET", "/users/42?page=2", nil), middleware, auth)
+
+		record := last()
+		if record["path"] != "/users/:id" {
+			t.Errorf("Expected route template as path, got %v", record["path"])
+		}
+		if record["user_id"] != "user123" {
+			t.Errorf("Expected user_id user123, got %v", record["user_id"])
+		}
+		if record["user_id_param"] != "42" {
+			t.Errorf("Expected custom field 42, got %v", record["user_id_param"])
+		}
+		for _, field := range []string{"query", "ip", "user_agent", "referer"} {
+			if _, ok := record[field]; ok {
+				t.Errorf("Field %s should not be logged", field)
+			}
+		}
+	})
+
+	t.Run("Default output is unchanged", func(t *testing.T) {
+		log, last := captureLog(t)
+		auth := func(next gin.HandlerFunc) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				SetUserID(c, "user123")
+				next(c)
+			}
+		}
+		req := httptest.NewRequest("GET", "/users/1?token=abc&page=2", nil)
+		req.Header.Set("Referer", "https://example.com/cb?token=abc")
+
+		serve(req, LoggerWith(WithAccessLogger(log)), auth)
+
+		record := last()
+		if record["query"] != "token=abc&page=2" || record["referer"] != "https://example.com/cb?token=abc" {
+			t.Errorf("Query and referer should not be redacted by default, got %v", record)
+		}
+		if _, ok := record["user_id"]; ok {
+			t.Error("user_id should only be logged when selected")
+		}
+		for _, field := range defaultLogFields[:10] {
+			if _, ok := record[field]; !ok {
+				t.Errorf("Default field %s should be logged", field)
+			}
+		}
+	})
+
+	t.Run("Redacts query parameters by name and pattern", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(
+			WithAccessLogger(log),
+			WithDefaultRedaction(),
+			WithLogRedactQuery("sig"),
+			WithLogRedactPattern(regexp.MustCompile(`(?i)secret`)),
+		)
+		req := httptest.NewRequest("GET", "/users/1?token=abc&page=2&SIG=x&client_secret=y", nil)
+		req.Header.Set("Referer", "https://example.com/cb?token=abc&next=%2Fhome")
+
+		serve(req, middleware)
+
+		record := last()
+		if want := "token=[REDACTED]&page=2&SIG=[REDACTED]&client_secret=[REDACTED]"; record["query"] != want {
+			t.Errorf("Expected query %q, got %v", want, record["query"])
+		}
+		if want := "https://example.com/cb?token=[REDACTED]&next=%2Fhome"; record["referer"] != want {
+			t.Errorf("Expected referer %q, got %v", want, record["referer"])
+		}
+	})
+
+	t.Run("Logs selected headers with redaction", func(t *testing.T) {
+		log, last := captureLog(t)
+		middleware := LoggerWith(
+			WithAccessLogger(log),
+			WithLogHeaders("Authorization", "X-Client-Version", "X-Partner-Secret", "X-Missing"),
+			WithLogRedactHeaders("Authorization"),
+			WithLogRedactPattern(regexp.MustCompile(`(?i)secret`)),
+		)
+		req := httptest.NewRequest("GET", "/users/1", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		req.Header.Set("X-Client-Version", "1.2.3")
+		req.Header.Set("X-Partner-Secret", "s3cr3t")
+
+		serve(req, middleware)
+
+		headers, ok := last()["headers"].(map[string]any)
+		if !ok {
+			t.Fatal("Expected a headers group")
+		}
+		if headers["authorization"] != "[REDACTED]" || headers["x-partner-secret"] != "[REDACTED]" {
+			t.Errorf("Secret headers should be redacted, got %v", headers)
+		}
+		if headers["x-client-version"] != "1.2.3" {
+			t.Errorf("Expected x-client-version 1.2.3, got %v", headers["x-client-version"])
+		}
+		if _, ok := headers["x-missing"]; ok {
+			t.Error("Absent headers should not be logged")
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package ginx
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// ============================================================================
+// Metrics - Prometheus RED Metrics and ginx Counters
+// ============================================================================
+
+// MetricsConfig metrics configuration
+type MetricsConfig struct {
+	Namespace       string               // Prefix of every metric name, optional
+	Registry        *prometheus.Registry // Registry the metrics are registered in, default a new registry
+	DurationBuckets []float64            // Latency histogram buckets in seconds, default prometheus.DefBuckets
+	SizeBuckets     []float64            // Response size histogram buckets in bytes, default 100B to 10MB
+}
+
+// WithMetricsNamespace prefixes every metric name, e.g. "myapp"
+func WithMetricsNamespace(namespace string) Option[MetricsConfig] {
+	return func(c *MetricsConfig) {
+		c.Namespace = namespace
+	}
+}
+
+// WithMetricsRegistry registers the metrics in an existing registry
+func WithMetricsRegistry(registry *prometheus.Registry) Option[MetricsConfig] {
+	return func(c *MetricsConfig) {
+		c.Registry = registry
+	}
+}
+
+// WithDurationBuckets sets the latency histogram buckets in seconds
+func WithDurationBuckets(buckets ...float64) Option[MetricsConfig] {
+	return func(c *MetricsConfig) {
+		c.DurationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the response size histogram buckets in bytes
+func WithSizeBuckets(buckets ...float64) Option[MetricsConfig] {
+	return func(c *MetricsConfig) {
+		c.SizeBuckets = buckets
+	}
+}
+
+// Metrics collects request metrics labelled by method, route template and status class.
+// Labels are bounded: unknown methods become "OTHER", unmatched routes "unmatched" and
+// statuses their class ("2xx").
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+
+	rateLimited  prometheus.Counter
+	timeouts     prometheus.Counter
+	panics       prometheus.Counter
+	cache        *prometheus.CounterVec
+	authFailures *prometheus.CounterVec
+}
+
+// NewMetrics creates and registers the request metrics:
+//   - http_requests_total, http_request_duration_seconds, http_response_size_bytes,
+//     labelled by method, route and status class
+//   - http_requests_in_flight
+//   - ginx_rate_limited_total (429 from RateLimit), ginx_timeouts_total (Timeout),
+//     ginx_panics_total (Recovery), ginx_cache_requests_total{result="hit|miss"} (Cache)
+//     and ginx_auth_failures_total{status="401|403"} (rejected by the auth, RBAC, scope, role or ABAC middlewares)
+func NewMetrics(options ...Option[MetricsConfig]) *Metrics {
+	config := &MetricsConfig{
+		DurationBuckets: prometheus.DefBuckets,
+		SizeBuckets:     prometheus.ExponentialBuckets(100, 10, 6),
+	}
+	for _, opt := range options {
+		opt(config)
+	}
+	if config.Registry == nil {
+		config.Registry = prometheus.NewRegistry()
+	}
+
+	labels := []string{"method", "route", "status"}
+	ns := config.Namespace
+	m := &Metrics{
+		registry: config.Registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "http_requests_total", Help: "Total number of HTTP requests.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "http_request_duration_seconds", Help: "HTTP request latency in seconds.",
+			Buckets: config.DurationBuckets,
+		}, labels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "http_response_size_bytes", Help: "HTTP response body size in bytes.",
+			Buckets: config.SizeBuckets,
+		}, labels),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns, Name: "http_requests_in_flight", Help: "Number of HTTP requests being served.",
+		}),
+		rateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "ginx_rate_limited_total", Help: "Requests rejected by RateLimit.",
+		}),
+		timeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "ginx_timeouts_total", Help: "Requests answered by Timeout.",
+		}),
+		panics: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: ns, Name: "ginx_panics_total", Help: "Panics recovered by Recovery.",
+		}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "ginx_cache_requests_total", Help: "Cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "ginx_auth_failures_total", Help: "Requests rejected by ginx auth middlewares as unauthenticated (401) or forbidden (403).",
+		}, []string{"status"}),
+	}
+	config.Registry.MustRegister(m.requests, m.duration, m.size, m.inFlight,
+		m.rateLimited, m.timeouts, m.panics, m.cache, m.authFailures)
+	return m
+}
+
+// Middleware records the metrics of each request.
+// Place it first in the chain so it observes the final status of every other middleware.
+func (m *Metrics) Middleware() Middleware {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			start := time.Now()
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			next(c)
+
+			status := c.Writer.Status()
+			labels := prometheus.Labels{
+				"method": metricsMethod(c.Request.Method),
+				"route":  metricsRoute(c),
+				"status": strconv.Itoa(status/100) + "xx",
+			}
+			m.requests.With(labels).Inc()
+			m.duration.With(labels).Observe(time.Since(start).Seconds())
+			if size := c.Writer.Size(); size >= 0 {
+				m.size.With(labels).Observe(float64(size))
+			}
+
+			if limited, _ := rateLimitDecision(c); limited {
+				m.rateLimited.Inc()
+			}
+			if IsTimeout(c) {
+				m.timeouts.Inc()
+			}
+			if _, ok := recoveredPanic(c); ok {
+				m.panics.Inc()
+			}
+			if hit, ok := c.Get(string(cacheHitKey)); ok {
+				if hit == true {
+					m.cache.WithLabelValues("hit").Inc()
+				} else {
+					m.cache.WithLabelValues("miss").Inc()
+				}
+			}
+			if failure, ok := authFailure(c); ok {
+				m.authFailures.WithLabelValues(strconv.Itoa(failure)).Inc()
+			}
+		}
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format, e.g. r.GET("/metrics", m.Handler())
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Registry returns the registry holding the metrics, to add application metrics
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// metricsMethod bounds the method label to the standard methods
+func metricsMethod(method string) string {
+	switch method {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "CONNECT", "TRACE":
+		return method
+	}
+	return "OTHER"
+}
+
+// metricsRoute uses the route template, never the raw path, to bound the route label
+func metricsRoute(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
@@ -0,0 +1,116 @@
+package ginx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	shardedcache "github.com/simp-lee/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(middlewares ...Middleware) (*gin.Engine, *Metrics) {
+		metrics := NewMetrics()
+		chain := NewChain().Use(metrics.Middleware())
+		for _, m := range middlewares {
+			chain.Use(m)
+		}
+		r := gin.New()
+		r.Use(chain.Build())
+		return r, metrics
+	}
+	serve := func(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	t.Run("should record RED metrics with bounded labels", func(t *testing.T) {
+		r, metrics := setup()
+		r.GET("/users/:id", func(c *gin.Context) { c.String(http.StatusOK, "hello") })
+		r.GET("/metrics", metrics.Handler())
+
+		serve(r, "GET", "/users/1")
+		serve(r, "GET", "/users/2")
+		serve(r, "GET", "/missing/1")
+		serve(r, "PURGE", "/missing/2")
+
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GET", "/users/:id", "2xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GET", "unmatched", "4xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("OTHER", "unmatched", "4xx")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(metrics.inFlight))
+
+		body := serve(r, "GET", "/metrics").Body.String()
+		assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 2`)
+		assert.Contains(t, body, `http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx"} 10`)
+		assert.Contains(t, body, "http_requests_in_flight")
+		assert.NotContains(t, body, "/users/1")
+	})
+
+	t.Run("should count rate limits, panics and auth failures", func(t *testing.T) {
+		store := NewMemoryLimiterStore(time.Minute)
+		defer store.Close()
+		r, metrics := setup(Recovery(), RateLimit(1, 1, WithStore(store)))
+		r.GET("/panic", func(c *gin.Context) { panic("boom") })
+		r.GET("/private", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+		r.GET("/admin", func(c *gin.Context) {
+			SetUserID(c, "user1")
+			SetUserRoles(c, []string{"user"})
+			RequireRoles("admin")(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		})
+
+		serve(r, "GET", "/panic")
+		serve(r, "GET", "/panic")
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.panics))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.rateLimited))
+
+		store.Clear()
+		serve(r, "GET", "/private")
+		assert.Equal(t, 0.0, testutil.ToFloat64(metrics.authFailures.WithLabelValues("401")), "handler responses are not auth failures")
+		store.Clear()
+		serve(r, "GET", "/admin")
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.authFailures.WithLabelValues("403")))
+	})
+
+	t.Run("should count timeouts", func(t *testing.T) {
+		r, metrics := setup(Timeout(WithTimeout(10 * time.Millisecond)))
+		r.GET("/slow", func(c *gin.Context) {
+			time.Sleep(30 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+
+		w := serve(r, "GET", "/slow")
+
+		assert.Equal(t, http.StatusRequestTimeout, w.Code)
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.timeouts))
+	})
+
+	t.Run("should count cache hits and misses", func(t *testing.T) {
+		cache := shardedcache.NewCache(shardedcache.Options{MaxSize: 100, DefaultExpiration: time.Minute})
+		r, metrics := setup(Cache(cache))
+		r.GET("/cached", func(c *gin.Context) { c.String(http.StatusOK, "data") })
+
+		serve(r, "GET", "/cached")
+		serve(r, "GET", "/cached")
+		serve(r, "GET", "/cached")
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.cache.WithLabelValues("miss")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(metrics.cache.WithLabelValues("hit")))
+	})
+
+	t.Run("should prefix metric names with the namespace", func(t *testing.T) {
+		metrics := NewMetrics(WithMetricsNamespace("shop"))
+		r := gin.New()
+		r.GET("/metrics", metrics.Handler())
+
+		body := serve(r, "GET", "/metrics").Body.String()
+		assert.True(t, strings.Contains(body, "shop_http_requests_in_flight"), body)
+	})
+}
@@ -0,0 +1,200 @@
+package ginx
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Client Certificate Mapping
+// ============================================================================
+
+// CertMapper maps a verified client certificate to an identity.
+// Returning an error rejects the certificate.
+type CertMapper func(cert *x509.Certificate) (*Identity, error)
+
+// CommonNameMapper uses the subject common name as user ID.
+// roles is optional and receives the common name.
+func CommonNameMapper(roles func(cn string) []string) CertMapper {
+	return func(cert *x509.Certificate) (*Identity, error) {
+		cn := cert.Subject.CommonName
+		if cn == "" {
+			return nil, errors.New("certificate has no common name")
+		}
+		identity := &Identity{UserID: cn}
+		if roles != nil {
+			identity.Roles = roles(cn)
+		}
+		return identity, nil
+	}
+}
+
+// SPIFFEIDMapper uses the SPIFFE ID (spiffe:// URI SAN) as user ID.
+// roles is optional and receives the SPIFFE ID.
+func SPIFFEIDMapper(roles func(id string) []string) CertMapper {
+	return func(cert *x509.Certificate) (*Identity, error) {
+		for _, uri := range cert.URIs {
+			if uri.Scheme == "spiffe" {
+				id := uri.String()
+				identity := &Identity{UserID: id}
+				if roles != nil {
+					identity.Roles = roles(id)
+				}
+				return identity, nil
+			}
+		}
+		return nil, errors.New("certificate has no SPIFFE ID")
+	}
+}
+
+// FingerprintMapper maps SHA-256 certificate fingerprints to identities.
+// Fingerprints are hex-encoded; case and ':' separators are ignored.
+func FingerprintMapper(identities map[string]Identity) CertMapper {
+	normalized := make(map[string]Identity, len(identities))
+	for fp, identity := range identities {
+		normalized[normalizeFingerprint(fp)] = identity
+	}
+	return func(cert *x509.Certificate) (*Identity, error) {
+		identity, exists := normalized[CertFingerprint(cert)]
+		if !exists {
+			return nil, errors.New("unknown certificate fingerprint")
+		}
+		identity.Roles = append([]string(nil), identity.Roles...)
+		return &identity, nil
+	}
+}
+
+// CertFingerprint returns the lowercase hex SHA-256 fingerprint of a certificate.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint lowercases a fingerprint and strips ':' separators
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(fp, ":", ""))
+}
+
+// ============================================================================
+// Middleware - mTLS Client Certificate Authentication
+// ============================================================================
+
+// ClientCertConfig client certificate authentication configuration
+type ClientCertConfig struct {
+	Mapper          CertMapper // Maps the leaf certificate to an identity, defaults to CommonNameMapper(nil)
+	AllowedIssuers  []string   // Issuer common names or full DNs allowed, empty allows any issuer
+	AllowUnverified bool       // Accept certificates the TLS stack did not verify (e.g. tls.RequestClientCert)
+}
+
+// defaultClientCertConfig returns default client certificate configuration
+func defaultClientCertConfig() *ClientCertConfig {
+	return &ClientCertConfig{
+		Mapper: CommonNameMapper(nil),
+	}
+}
+
+// WithCertMapper sets the certificate to identity mapper
+func WithCertMapper(mapper CertMapper) Option[ClientCertConfig] {
+	return func(c *ClientCertConfig) {
+		c.Mapper = mapper
+	}
+}
+
+// WithAllowedIssuers restricts accepted certificates to the given issuers (common name or full DN)
+func WithAllowedIssuers(issuers ...string) Option[ClientCertConfig] {
+	return func(c *ClientCertConfig) {
+		c.AllowedIssuers = issuers
+	}
+}
+
+// WithUnverifiedCerts accepts client certificates that were not verified during the TLS handshake.
+// Only use this when verification happens elsewhere, e.g. in a custom VerifyPeerCertificate.
+func WithUnverifiedCerts() Option[ClientCertConfig] {
+	return func(c *ClientCertConfig) {
+		c.AllowUnverified = true
+	}
+}
+
+// ClientCertAuth is mTLS client certificate authentication middleware.
+// It reads c.Request.TLS.PeerCertificates, maps the leaf certificate to an identity
+// and sets the same context keys as Auth, so RBAC middlewares can be reused.
+// The server must request client certificates (tls.Config.ClientAuth).
+func ClientCertAuth(options ...Option[ClientCertConfig]) Middleware {
+	authenticator := ClientCertAuthenticator(options...)
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			identity, err := authenticator.Authenticate(c)
+			if err != nil {
+				if errors.Is(err, ErrNoCredentials) {
+					abortAuthFailure(c, 401, gin.H{"error": "client certificate required"})
+				} else {
+					abortAuthFailure(c, 401, gin.H{"error": "invalid client certificate"})
+				}
+				return
+			}
+
+			SetIdentity(c, identity)
+			next(c)
+		}
+	}
+}
+
+// clientCertAuthenticator authenticates requests by their TLS client certificate
+type clientCertAuthenticator struct {
+	config *ClientCertConfig
+}
+
+// ClientCertAuthenticator creates an Authenticator for mTLS client certificates, for use with AuthAny/AuthAll.
+func ClientCertAuthenticator(options ...Option[ClientCertConfig]) Authenticator {
+	config := defaultClientCertConfig()
+	for _, option := range options {
+		option(config)
+	}
+	if config.Mapper == nil {
+		config.Mapper = CommonNameMapper(nil)
+	}
+	return &clientCertAuthenticator{config: config}
+}
+
+// Authenticate validates the client certificate and maps it to an identity
+func (a *clientCertAuthenticator) Authenticate(c *gin.Context) (*Identity, error) {
+	state := c.Request.TLS
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil, ErrNoCredentials
+	}
+	if !a.config.AllowUnverified && len(state.VerifiedChains) == 0 {
+		return nil, fmt.Errorf("%w: certificate not verified", ErrInvalidCredentials)
+	}
+
+	cert := state.PeerCertificates[0]
+	if len(a.config.AllowedIssuers) > 0 &&
+		!slices.Contains(a.config.AllowedIssuers, cert.Issuer.CommonName) &&
+		!slices.Contains(a.config.AllowedIssuers, cert.Issuer.String()) {
+		return nil, fmt.Errorf("%w: issuer %q not allowed", ErrInvalidCredentials, cert.Issuer.String())
+	}
+
+	identity, err := a.config.Mapper(cert)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if identity == nil {
+		return nil, fmt.Errorf("%w: certificate not mapped to an identity", ErrInvalidCredentials)
+	}
+	if identity.Scheme == "" {
+		identity.Scheme = "mTLS"
+	}
+	return identity, nil
+}
+
+// Challenge returns no challenge; client certificates are negotiated by TLS, not HTTP
+func (a *clientCertAuthenticator) Challenge(c *gin.Context) string {
+	return ""
+}
@@ -0,0 +1,228 @@
+package ginx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert bundles a generated certificate and its key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert generates a certificate signed by parent (self-signed if parent is nil)
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool, uris ...string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"ginx"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{"localhost"}
+	}
+	for _, raw := range uris {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+// tlsContext creates a test context with the given peer certificate
+func tlsContext(cert *x509.Certificate, verified bool) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := TestContext("GET", "/test", nil)
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	if verified {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+	c.Request.TLS = state
+	return c, w
+}
+
+func TestClientCertAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ca := newTestCert(t, "Test CA", nil, true)
+	client := newTestCert(t, "orders-service", ca, false, "spiffe://example.org/ns/prod/sa/orders")
+
+	t.Run("should map common name by default", func(t *testing.T) {
+		c, w := tlsContext(client.cert, true)
+
+		var userID, scheme string
+		ClientCertAuth()(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			scheme, _ = GetAuthScheme(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "orders-service", userID)
+		assert.Equal(t, "mTLS", scheme)
+	})
+
+	t.Run("should map SPIFFE ID with roles", func(t *testing.T) {
+		c, w := tlsContext(client.cert, true)
+
+		var userID string
+		var roles []string
+		ClientCertAuth(WithCertMapper(SPIFFEIDMapper(func(id string) []string {
+			return []string{"service"}
+		})))(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			roles, _ = GetUserRoles(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "spiffe://example.org/ns/prod/sa/orders", userID)
+		assert.Equal(t, []string{"service"}, roles)
+	})
+
+	t.Run("should map fingerprint", func(t *testing.T) {
+		fp := strings.ToUpper(CertFingerprint(client.cert))
+		mapper := FingerprintMapper(map[string]Identity{
+			fp[:2] + ":" + fp[2:]: {UserID: "pinned", Roles: []string{"admin"}},
+		})
+		c, w := tlsContext(client.cert, true)
+
+		var userID string
+		ClientCertAuth(WithCertMapper(mapper))(func(c *gin.Context) {
+			userID, _ = GetUserID(c)
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "pinned", userID)
+
+		other := newTestCert(t, "other", ca, false)
+		c, w = tlsContext(other.cert, true)
+		ClientCertAuth(WithCertMapper(mapper))(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject a nil identity from a custom mapper", func(t *testing.T) {
+		c, w := tlsContext(client.cert, true)
+
+		ClientCertAuth(WithCertMapper(func(cert *x509.Certificate) (*Identity, error) {
+			return nil, nil
+		}))(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid client certificate")
+	})
+
+	t.Run("should require a certificate", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+
+		ClientCertAuth()(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "client certificate required")
+	})
+
+	t.Run("should reject unverified certificate unless allowed", func(t *testing.T) {
+		c, w := tlsContext(client.cert, false)
+		ClientCertAuth()(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid client certificate")
+
+		c, w = tlsContext(client.cert, false)
+		ClientCertAuth(WithUnverifiedCerts())(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should enforce issuer allow-list", func(t *testing.T) {
+		c, w := tlsContext(client.cert, true)
+		ClientCertAuth(WithAllowedIssuers("Other CA"))(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		c, w = tlsContext(client.cert, true)
+		ClientCertAuth(WithAllowedIssuers("Test CA"))(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		c, w = tlsContext(client.cert, true)
+		ClientCertAuth(WithAllowedIssuers(ca.cert.Subject.String()))(func(c *gin.Context) { c.Status(http.StatusOK) })(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should work with RequirePermission over a real TLS handshake", func(t *testing.T) {
+		server := newTestCert(t, "localhost", ca, false)
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "orders-service", "orders", "read").Return(true, nil)
+
+		r := gin.New()
+		r.Use(NewChain().
+			Use(ClientCertAuth()).
+			Use(RequirePermission(mockRBAC, "orders", "read")).
+			Build())
+		r.GET("/orders", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+		ts := httptest.NewUnstartedServer(r)
+		ts.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+			Certificates: []tls.Certificate{{
+				Certificate: [][]byte{server.cert.Raw},
+				PrivateKey:  server.key,
+			}},
+		}
+		ts.StartTLS()
+		defer ts.Close()
+
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs: pool,
+			Certificates: []tls.Certificate{{
+				Certificate: [][]byte{client.cert.Raw},
+				PrivateKey:  client.key,
+			}},
+		}}}
+		resp, err := httpClient.Get(strings.Replace(ts.URL, "127.0.0.1", "localhost", 1) + "/orders")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockRBAC.AssertExpectations(t)
+	})
+}
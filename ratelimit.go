@@ -21,7 +21,7 @@ for precise, high-performance rate limiting with minimal overhead.
 Key Features:
   - Token bucket algorithm for smooth rate limiting
   - Configurable storage backends (memory included, Redis support via interface)
-  - Per-IP, per-user, and custom key-based rate limiting
+  - Per-IP, per-user, per-tenant, and custom key-based rate limiting
   - HTTP header support (X-RateLimit-* headers)
   - Dynamic rate limiting with per-key limits
   - Waiting middleware variant for traffic smoothing
@@ -38,6 +38,9 @@ Basic Usage:
 	// Per-path rate limiting (different limits per endpoint)
 	r.Use(ginx.RateLimit(10, 20, ginx.WithPath()))
 
+	// Per-tenant rate limiting (requires the Tenant middleware)
+	r.Use(ginx.RateLimit(500, 1000, ginx.WithTenant()))
+
 Advanced Usage:
 
 	// Multiple options combined
@@ -278,6 +281,7 @@ func (rl *rateLimiter) standardMiddleware() Middleware {
 				if rl.retryAfterHeader {
 					c.Header("Retry-After", "1")
 				}
+				c.Set(string(rateLimitKey), true)
 				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 					"error":       "rate limit exceeded",
 					"retry_after": 1,
@@ -296,6 +300,7 @@ func (rl *rateLimiter) standardMiddleware() Middleware {
 				rl.setHeaders(c, limiter)
 			}
 
+			c.Set(string(rateLimitKey), false)
 			next(c)
 		}
 	}
@@ -343,6 +348,7 @@ func (rl *rateLimiter) waitMiddleware() Middleware {
 					c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
 				}
 
+				c.Set(string(rateLimitKey), true)
 				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 					"error":       "rate limit exceeded",
 					"timeout":     rl.waitTimeout.Seconds(),
@@ -355,6 +361,7 @@ func (rl *rateLimiter) waitMiddleware() Middleware {
 				rl.setHeaders(c, limiter)
 			}
 
+			c.Set(string(rateLimitKey), false)
 			next(c)
 		}
 	}
@@ -430,6 +437,8 @@ func (rl *rateLimiter) getLimiter(key string) *rate.Limiter {
 
 // handleRateLimit processes a rate-limited request and sends appropriate response.
 func (rl *rateLimiter) handleRateLimit(c *gin.Context, limiter *rate.Limiter) {
+	c.Set(string(rateLimitKey), true)
+
 	// Use Reserve to get accurate wait time without consuming a token
 	reservation := limiter.Reserve()
 	if !reservation.OK() {
@@ -464,6 +473,16 @@ func (rl *rateLimiter) handleRateLimit(c *gin.Context, limiter *rate.Limiter) {
 	})
 }
 
+// rateLimitDecision reports whether RateLimit rejected the request, if it ran
+func rateLimitDecision(c *gin.Context) (limited bool, ok bool) {
+	value, exists := c.Get(string(rateLimitKey))
+	if !exists {
+		return false, false
+	}
+	limited, ok = value.(bool)
+	return limited, ok
+}
+
 // setHeaders adds X-RateLimit-* headers to the response.
 func (rl *rateLimiter) setHeaders(c *gin.Context, limiter *rate.Limiter) {
 	// Get actual limits from limiter (handles both static and dynamic limits correctly)
@@ -529,6 +548,20 @@ func WithUser() RateOption {
 	}
 }
 
+// WithTenant configures rate limiting by tenant, so all clients of a tenant share a bucket.
+// Falls back to IP-based limiting if no tenant is found.
+// Tenants are identified by GetTenantID, see the Tenant middleware.
+func WithTenant() RateOption {
+	return func(rl *rateLimiter) {
+		rl.keyFunc = func(c *gin.Context) string {
+			if tenantID, exists := GetTenantID(c); exists {
+				return "tenant:" + tenantID
+			}
+			return c.ClientIP() // Fallback to IP
+		}
+	}
+}
+
 // WithPath configures rate limiting by IP and path combination.
 // This allows different rate limits for different endpoints per client.
 func WithPath() RateOption {
@@ -1,6 +1,9 @@
 package ginx
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/simp-lee/rbac"
 )
@@ -13,23 +16,9 @@ import (
 func RequirePermission(service rbac.Service, resource, action string) Middleware {
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(c *gin.Context) {
-			userID, ok := GetUserIDOrAbort(c)
-			if !ok {
-				return
+			if authorizePermission(c, service, checkCombined, resource, action, "permission denied") {
+				next(c)
 			}
-
-			hasPermission, err := service.HasPermission(userID, resource, action)
-			if err != nil {
-				c.AbortWithStatusJSON(500, gin.H{"error": "permission check failed"})
-				return
-			}
-
-			if !hasPermission {
-				c.AbortWithStatusJSON(403, gin.H{"error": "permission denied"})
-				return
-			}
-
-			next(c)
 		}
 	}
 }
@@ -38,52 +27,189 @@ func RequirePermission(service rbac.Service, resource, action string) Middleware
 func RequireRolePermission(service rbac.Service, resource, action string) Middleware {
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(c *gin.Context) {
-			userID, ok := GetUserIDOrAbort(c)
-			if !ok {
-				return
+			if authorizePermission(c, service, checkRole, resource, action, "insufficient role permissions") {
+				next(c)
 			}
+		}
+	}
+}
 
-			hasPermission, err := service.HasRolePermission(userID, resource, action)
-			if err != nil {
-				c.AbortWithStatusJSON(500, gin.H{"error": "permission check failed"})
-				return
+// RequireUserPermission based on direct user permission only checking middleware
+func RequireUserPermission(service rbac.Service, resource, action string) Middleware {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if authorizePermission(c, service, checkUser, resource, action, "insufficient user permissions") {
+				next(c)
 			}
+		}
+	}
+}
 
-			if !hasPermission {
-				c.AbortWithStatusJSON(403, gin.H{"error": "insufficient role permissions"})
-				return
+// PermissionFunc derives the resource and action to check from the request.
+// Returning an empty resource or action denies the request.
+type PermissionFunc func(c *gin.Context) (resource, action string)
+
+// RequirePermissionFunc checks combined role and direct user permissions for a
+// resource and action derived from the request, e.g. "project:" + c.Param("id")
+func RequirePermissionFunc(service rbac.Service, fn PermissionFunc) Middleware {
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			resource, action := fn(c)
+			if authorizePermission(c, service, checkCombined, resource, action, "permission denied") {
+				next(c)
 			}
+		}
+	}
+}
 
-			next(c)
+// authorizePermission checks a single permission, records the decision and writes the
+// response when the request is not allowed
+func authorizePermission(c *gin.Context, service rbac.Service, kind, resource, action, deniedMessage string) bool {
+	userID, ok := GetUserIDOrAbort(c)
+	if !ok {
+		auditDecision(c, resource, action, AuditDeny, "user not authenticated")
+		return false
+	}
+
+	if resource == "" || action == "" {
+		auditDecision(c, resource, action, AuditDeny, "no resource or action")
+		abortAuthFailure(c, 403, gin.H{"error": deniedMessage})
+		return false
+	}
+
+	hasPermission, err := checkPermission(c, service, kind, userID, resource, action)
+	if err != nil {
+		if reportPermissionFailure(c, err, resource) {
+			auditDecision(c, resource, action, AuditAllow, "fail-open: "+err.Error())
+			return true
+		}
+		auditDecision(c, resource, action, AuditError, err.Error())
+		abortPermissionFailure(c)
+		return false
+	}
+
+	if !hasPermission {
+		auditDecision(c, resource, action, AuditDeny, deniedMessage)
+		abortAuthFailure(c, 403, gin.H{"error": deniedMessage})
+		return false
+	}
+
+	auditDecision(c, resource, action, AuditAllow, "")
+	return true
+}
+
+// ParamPermission builds a PermissionFunc using prefix plus the named route parameter
+// as resource and the conventional action of the HTTP method (see MethodAction)
+func ParamPermission(prefix, param string) PermissionFunc {
+	return func(c *gin.Context) (string, string) {
+		id := c.Param(param)
+		if id == "" {
+			return "", ""
 		}
+		return prefix + id, MethodAction(c.Request.Method)
 	}
 }
 
-// RequireUserPermission based on direct user permission only checking middleware
-func RequireUserPermission(service rbac.Service, resource, action string) Middleware {
+// MethodAction maps an HTTP method to its conventional action:
+// GET/HEAD → read, POST → create, PUT/PATCH → update, DELETE → delete.
+// Other methods map to "".
+func MethodAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return "read"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return ""
+}
+
+// RequireAllPermissions requires every permission in the set, checked in a single pass.
+// The 403 response lists the missing permissions as "resource:action".
+func RequireAllPermissions(service rbac.Service, permissions ...rbac.Permission) Middleware {
+	return requirePermissionSet(service, permissions, true)
+}
+
+// RequireAnyPermission requires at least one permission in the set, checked in a single pass.
+// The 403 response lists the permissions of which none was granted.
+func RequireAnyPermission(service rbac.Service, permissions ...rbac.Permission) Middleware {
+	return requirePermissionSet(service, permissions, false)
+}
+
+// requirePermissionSet provides the internal composite permission middleware implementation
+func requirePermissionSet(service rbac.Service, permissions []rbac.Permission, all bool) Middleware {
+	mustHavePermissions(permissions)
+	resource, action := permissionSetAudit(permissions, all)
+
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(c *gin.Context) {
 			userID, ok := GetUserIDOrAbort(c)
 			if !ok {
+				auditDecision(c, resource, action, AuditDeny, "user not authenticated")
 				return
 			}
 
-			hasPermission, err := service.HasUserPermission(userID, resource, action)
+			results, err := checkPermissions(c, service, userID, permissions)
 			if err != nil {
-				c.AbortWithStatusJSON(500, gin.H{"error": "permission check failed"})
+				if reportPermissionFailure(c, err, permissionResources(permissions)...) {
+					auditDecision(c, resource, action, AuditAllow, "fail-open: "+err.Error())
+					next(c)
+					return
+				}
+				auditDecision(c, resource, action, AuditError, err.Error())
+				abortPermissionFailure(c)
 				return
 			}
 
-			if !hasPermission {
-				c.AbortWithStatusJSON(403, gin.H{"error": "insufficient user permissions"})
+			if missing, allowed := evaluatePermissionSet(permissions, results, all); !allowed {
+				auditDecision(c, resource, action, AuditDeny, "missing "+strings.Join(missing, " "))
+				abortAuthFailure(c, 403, gin.H{"error": "permission denied", "missing": missing})
 				return
 			}
 
+			auditDecision(c, resource, action, AuditAllow, "")
 			next(c)
 		}
 	}
 }
 
+// permissionSetAudit describes a permission set for audit events: the resource lists the
+// permissions as "resource:action" and the action tells whether all or any are required
+func permissionSetAudit(permissions []rbac.Permission, all bool) (string, string) {
+	names := make([]string, len(permissions))
+	for i, perm := range permissions {
+		names[i] = perm.Resource + ":" + perm.Action
+	}
+	if all {
+		return strings.Join(names, " "), "all"
+	}
+	return strings.Join(names, " "), "any"
+}
+
+// mustHavePermissions rejects an empty permission set, which "all" would otherwise grant
+func mustHavePermissions(permissions []rbac.Permission) {
+	if len(permissions) == 0 {
+		panic("RBAC configuration error: empty permission set")
+	}
+}
+
+// evaluatePermissionSet reports whether the set is satisfied and which permissions were not granted
+func evaluatePermissionSet(permissions []rbac.Permission, results map[rbac.Permission]bool, all bool) ([]string, bool) {
+	missing := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		if !results[perm] {
+			missing = append(missing, perm.Resource+":"+perm.Action)
+		}
+	}
+	if all {
+		return missing, len(missing) == 0
+	}
+	return missing, len(missing) < len(permissions)
+}
+
 // ============================================================================
 // Conditions - RBAC Authorization
 // ============================================================================
@@ -103,8 +229,7 @@ func HasPermission(service rbac.Service, resource, action string) Condition {
 		if !exists {
 			return false
 		}
-		hasPermission, err := service.HasPermission(userID, resource, action)
-		return err == nil && hasPermission
+		return conditionPermission(c, service, checkCombined, userID, resource, action)
 	}
 }
 
@@ -115,8 +240,7 @@ func HasRolePermission(service rbac.Service, resource, action string) Condition
 		if !exists {
 			return false
 		}
-		hasPermission, err := service.HasRolePermission(userID, resource, action)
-		return err == nil && hasPermission
+		return conditionPermission(c, service, checkRole, userID, resource, action)
 	}
 }
 
@@ -127,7 +251,67 @@ func HasUserPermission(service rbac.Service, resource, action string) Condition
 		if !exists {
 			return false
 		}
-		hasPermission, err := service.HasUserPermission(userID, resource, action)
-		return err == nil && hasPermission
+		return conditionPermission(c, service, checkUser, userID, resource, action)
+	}
+}
+
+// HasPermissionFunc checks combined permissions for a resource and action derived from the request
+func HasPermissionFunc(service rbac.Service, fn PermissionFunc) Condition {
+	return func(c *gin.Context) bool {
+		userID, exists := GetUserID(c)
+		if !exists {
+			return false
+		}
+		resource, action := fn(c)
+		if resource == "" || action == "" {
+			return false
+		}
+		return conditionPermission(c, service, checkCombined, userID, resource, action)
+	}
+}
+
+// HasAllPermissions checks that every permission in the set is granted
+func HasAllPermissions(service rbac.Service, permissions ...rbac.Permission) Condition {
+	return hasPermissionSet(service, permissions, true)
+}
+
+// HasAnyPermission checks that at least one permission in the set is granted
+func HasAnyPermission(service rbac.Service, permissions ...rbac.Permission) Condition {
+	return hasPermissionSet(service, permissions, false)
+}
+
+// hasPermissionSet provides the internal composite permission condition implementation
+func hasPermissionSet(service rbac.Service, permissions []rbac.Permission, all bool) Condition {
+	mustHavePermissions(permissions)
+	return func(c *gin.Context) bool {
+		userID, exists := GetUserID(c)
+		if !exists {
+			return false
+		}
+		results, err := checkPermissions(c, service, userID, permissions)
+		if err != nil {
+			return reportPermissionFailure(c, err, permissionResources(permissions)...)
+		}
+		_, allowed := evaluatePermissionSet(permissions, results, all)
+		return allowed
+	}
+}
+
+// conditionPermission checks a single permission for a condition; failed checks count as
+// not granted unless the RBAC failure policy lists the resource as fail-open
+func conditionPermission(c *gin.Context, service rbac.Service, kind, userID, resource, action string) bool {
+	hasPermission, err := checkPermission(c, service, kind, userID, resource, action)
+	if err != nil {
+		return reportPermissionFailure(c, err, resource)
+	}
+	return hasPermission
+}
+
+// permissionResources returns the resources of a permission set
+func permissionResources(permissions []rbac.Permission) []string {
+	resources := make([]string, len(permissions))
+	for i, perm := range permissions {
+		resources[i] = perm.Resource
 	}
+	return resources
 }
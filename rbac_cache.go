@@ -0,0 +1,300 @@
+package ginx
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	shardedcache "github.com/simp-lee/cache"
+	"github.com/simp-lee/rbac"
+)
+
+// ============================================================================
+// RBAC Decision Caching
+// ============================================================================
+
+// Permission check kinds, matching the rbac.Service method they memoize
+const (
+	checkCombined = "perm" // HasPermission
+	checkRole     = "role" // HasRolePermission
+	checkUser     = "user" // HasUserPermission
+)
+
+// permissionKey identifies a memoized permission decision
+type permissionKey struct {
+	kind, userID, resource, action string
+}
+
+// requestDecisions returns the per-request decision map stored on the context
+func requestDecisions(c *gin.Context) map[permissionKey]bool {
+	if value, exists := c.Get(string(rbacDecisionsKey)); exists {
+		if decisions, ok := value.(map[permissionKey]bool); ok {
+			return decisions
+		}
+	}
+	decisions := make(map[permissionKey]bool)
+	c.Set(string(rbacDecisionsKey), decisions)
+	return decisions
+}
+
+// checkPermission calls the rbac.Service method for kind, memoizing the decision for the
+// rest of the request so stacked conditions and middlewares hit the service once.
+// Errors are retried per the RBAC failure policy and not memoized.
+func checkPermission(c *gin.Context, service rbac.Service, kind, userID, resource, action string) (bool, error) {
+	decisions := requestDecisions(c)
+	key := permissionKey{kind, userID, resource, action}
+	if allowed, ok := decisions[key]; ok {
+		return allowed, nil
+	}
+
+	check := service.HasPermission
+	switch kind {
+	case checkRole:
+		check = service.HasRolePermission
+	case checkUser:
+		check = service.HasUserPermission
+	}
+	allowed, err := withRBACRetries(c, func() (bool, error) {
+		return check(userID, resource, action)
+	})
+	if err != nil {
+		return false, err
+	}
+	decisions[key] = allowed
+	return allowed, nil
+}
+
+// checkPermissions resolves a permission set in a single CheckMultiplePermissions call,
+// reusing and filling the per-request decisions of HasPermission
+func checkPermissions(c *gin.Context, service rbac.Service, userID string, permissions []rbac.Permission) (map[rbac.Permission]bool, error) {
+	decisions := requestDecisions(c)
+	results := make(map[rbac.Permission]bool, len(permissions))
+	var pending []rbac.Permission
+	for _, perm := range permissions {
+		if allowed, ok := decisions[permissionKey{checkCombined, userID, perm.Resource, perm.Action}]; ok {
+			results[perm] = allowed
+		} else {
+			pending = append(pending, perm)
+		}
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	checked, err := withRBACRetries(c, func() (map[rbac.Permission]bool, error) {
+		return service.CheckMultiplePermissions(userID, pending)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, perm := range pending {
+		results[perm] = checked[perm]
+		decisions[permissionKey{checkCombined, userID, perm.Resource, perm.Action}] = checked[perm]
+	}
+	return results, nil
+}
+
+// CachedRBAC wraps an rbac.Service with a decision cache shared across requests.
+// Decisions are keyed by check kind, user, resource and action and expire after the TTL.
+// Changes made through the wrapper invalidate the affected entries: user assignments and
+// user permissions drop that user's decisions, role changes drop every decision.
+// Changes made elsewhere (another instance, direct storage writes) must be reported through
+// InvalidateUser/InvalidateAll, or an invalidation source (see WithInvalidationSource).
+type CachedRBAC struct {
+	rbac.Service
+	decisions shardedcache.Group
+	ttl       time.Duration
+}
+
+// CachedRBACConfig RBAC decision cache configuration
+type CachedRBACConfig struct {
+	Group string // Cache group name, default "ginx.rbac"
+	// Source subscribes to external change notifications; the callback receives a user ID,
+	// or "" to invalidate every user
+	Source func(invalidate func(userID string))
+}
+
+// WithRBACCacheGroup sets the cache group used for RBAC decisions
+func WithRBACCacheGroup(name string) Option[CachedRBACConfig] {
+	return func(c *CachedRBACConfig) {
+		c.Group = name
+	}
+}
+
+// WithInvalidationSource registers an external change feed, such as a pub/sub
+// subscription, that invalidates cached decisions when roles or permissions change
+func WithInvalidationSource(source func(invalidate func(userID string))) Option[CachedRBACConfig] {
+	return func(c *CachedRBACConfig) {
+		c.Source = source
+	}
+}
+
+// NewCachedRBAC creates a caching rbac.Service storing decisions in the given cache
+func NewCachedRBAC(service rbac.Service, cache shardedcache.CacheInterface, ttl time.Duration, options ...Option[CachedRBACConfig]) *CachedRBAC {
+	config := &CachedRBACConfig{Group: "ginx.rbac"}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	cached := &CachedRBAC{
+		Service:   service,
+		decisions: cache.Group(config.Group),
+		ttl:       ttl,
+	}
+	if config.Source != nil {
+		config.Source(func(userID string) {
+			if userID == "" {
+				cached.InvalidateAll()
+				return
+			}
+			cached.InvalidateUser(userID)
+		})
+	}
+	return cached
+}
+
+// InvalidateUser drops every cached decision of the user
+func (s *CachedRBAC) InvalidateUser(userID string) {
+	s.decisions.DeletePrefix(userID + "\x00")
+}
+
+// InvalidateAll drops every cached decision
+func (s *CachedRBAC) InvalidateAll() {
+	s.decisions.Clear()
+}
+
+// HasPermission checks combined role and direct user permissions through the cache
+func (s *CachedRBAC) HasPermission(userID, resource, action string) (bool, error) {
+	return s.cached(checkCombined, userID, resource, action, s.Service.HasPermission)
+}
+
+// HasRolePermission checks role based permissions through the cache
+func (s *CachedRBAC) HasRolePermission(userID, resource, action string) (bool, error) {
+	return s.cached(checkRole, userID, resource, action, s.Service.HasRolePermission)
+}
+
+// HasUserPermission checks direct user permissions through the cache
+func (s *CachedRBAC) HasUserPermission(userID, resource, action string) (bool, error) {
+	return s.cached(checkUser, userID, resource, action, s.Service.HasUserPermission)
+}
+
+// CheckMultiplePermissions checks a permission set, querying only uncached permissions
+func (s *CachedRBAC) CheckMultiplePermissions(userID string, permissions []rbac.Permission) (map[rbac.Permission]bool, error) {
+	results := make(map[rbac.Permission]bool, len(permissions))
+	var pending []rbac.Permission
+	for _, perm := range permissions {
+		if value, ok := s.decisions.Get(decisionCacheKey(checkCombined, userID, perm.Resource, perm.Action)); ok {
+			results[perm] = value.(bool)
+		} else {
+			pending = append(pending, perm)
+		}
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	checked, err := s.Service.CheckMultiplePermissions(userID, pending)
+	if err != nil {
+		return nil, err
+	}
+	for _, perm := range pending {
+		results[perm] = checked[perm]
+		s.decisions.SetWithExpiration(decisionCacheKey(checkCombined, userID, perm.Resource, perm.Action), checked[perm], s.ttl)
+	}
+	return results, nil
+}
+
+// cached returns a cached decision or computes and stores it
+func (s *CachedRBAC) cached(kind, userID, resource, action string, check func(userID, resource, action string) (bool, error)) (bool, error) {
+	key := decisionCacheKey(kind, userID, resource, action)
+	if value, ok := s.decisions.Get(key); ok {
+		return value.(bool), nil
+	}
+
+	allowed, err := check(userID, resource, action)
+	if err != nil {
+		return false, err
+	}
+	s.decisions.SetWithExpiration(key, allowed, s.ttl)
+	return allowed, nil
+}
+
+// decisionCacheKey builds a cache key prefixed by the user ID for per-user invalidation
+func decisionCacheKey(kind, userID, resource, action string) string {
+	return userID + "\x00" + kind + "\x00" + resource + "\x00" + action
+}
+
+// ============================================================================
+// CachedRBAC - Invalidating Mutations
+// ============================================================================
+
+// AssignRole assigns a role and invalidates the user's decisions
+func (s *CachedRBAC) AssignRole(userID, roleID string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.AssignRole(userID, roleID)
+}
+
+// UnassignRole removes a role and invalidates the user's decisions
+func (s *CachedRBAC) UnassignRole(userID, roleID string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.UnassignRole(userID, roleID)
+}
+
+// DeleteRole deletes a role and invalidates every decision
+func (s *CachedRBAC) DeleteRole(roleID string) error {
+	defer s.InvalidateAll()
+	return s.Service.DeleteRole(roleID)
+}
+
+// AddRolePermission adds a role permission and invalidates every decision
+func (s *CachedRBAC) AddRolePermission(roleID, resource, action string) error {
+	defer s.InvalidateAll()
+	return s.Service.AddRolePermission(roleID, resource, action)
+}
+
+// AddRolePermissions adds role permissions and invalidates every decision
+func (s *CachedRBAC) AddRolePermissions(roleID, resource string, actions []string) error {
+	defer s.InvalidateAll()
+	return s.Service.AddRolePermissions(roleID, resource, actions)
+}
+
+// RemoveRolePermission removes a role permission and invalidates every decision
+func (s *CachedRBAC) RemoveRolePermission(roleID, resource, action string) error {
+	defer s.InvalidateAll()
+	return s.Service.RemoveRolePermission(roleID, resource, action)
+}
+
+// RemoveRolePermissions removes role permissions and invalidates every decision
+func (s *CachedRBAC) RemoveRolePermissions(roleID, resource string) error {
+	defer s.InvalidateAll()
+	return s.Service.RemoveRolePermissions(roleID, resource)
+}
+
+// AddUserPermission adds a user permission and invalidates the user's decisions
+func (s *CachedRBAC) AddUserPermission(userID, resource, action string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.AddUserPermission(userID, resource, action)
+}
+
+// AddUserPermissions adds user permissions and invalidates the user's decisions
+func (s *CachedRBAC) AddUserPermissions(userID, resource string, actions []string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.AddUserPermissions(userID, resource, actions)
+}
+
+// RemoveUserPermission removes a user permission and invalidates the user's decisions
+func (s *CachedRBAC) RemoveUserPermission(userID, resource, action string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.RemoveUserPermission(userID, resource, action)
+}
+
+// RemoveUserPermissions removes user permissions and invalidates the user's decisions
+func (s *CachedRBAC) RemoveUserPermissions(userID, resource string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.RemoveUserPermissions(userID, resource)
+}
+
+// RemoveAllUserPermissions removes all user permissions and invalidates the user's decisions
+func (s *CachedRBAC) RemoveAllUserPermissions(userID string) error {
+	defer s.InvalidateUser(userID)
+	return s.Service.RemoveAllUserPermissions(userID)
+}
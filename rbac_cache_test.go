@@ -0,0 +1,215 @@
+package ginx
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	shardedcache "github.com/simp-lee/cache"
+	"github.com/simp-lee/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDecisionCache() shardedcache.CacheInterface {
+	return shardedcache.NewCache(shardedcache.Options{
+		MaxSize:           100,
+		DefaultExpiration: time.Minute,
+		ShardCount:        4,
+	})
+}
+
+func TestPerRequestPermissionMemoization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("should check each permission once per request", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(true, nil).Once()
+
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		var executed []string
+		handler := NewChain().
+			When(HasPermission(mockRBAC, "posts", "edit"), TestMiddleware("editor", &executed)).
+			Use(RequirePermission(mockRBAC, "posts", "edit")).
+			Build()
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"editor"}, executed)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should keep check kinds apart", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(true, nil).Once()
+		mockRBAC.On("HasRolePermission", "user123", "posts", "edit").Return(false, nil).Once()
+
+		c, _ := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		assert.True(t, HasPermission(mockRBAC, "posts", "edit")(c))
+		assert.False(t, HasRolePermission(mockRBAC, "posts", "edit")(c))
+		assert.True(t, HasPermission(mockRBAC, "posts", "edit")(c))
+		assert.False(t, HasRolePermission(mockRBAC, "posts", "edit")(c))
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should not memoize errors", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(false, errors.New("database error")).Once()
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(true, nil).Once()
+
+		c, _ := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		assert.False(t, HasPermission(mockRBAC, "posts", "edit")(c))
+		assert.True(t, HasPermission(mockRBAC, "posts", "edit")(c))
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should reuse decisions for permission sets", func(t *testing.T) {
+		read := rbac.Permission{Resource: "orders", Action: "read"}
+		write := rbac.Permission{Resource: "orders", Action: "write"}
+
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "orders", "read").Return(true, nil).Once()
+		mockRBAC.On("CheckMultiplePermissions", "user123", []rbac.Permission{write}).
+			Return(map[rbac.Permission]bool{write: true}, nil).Once()
+
+		c, _ := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		assert.True(t, HasPermission(mockRBAC, "orders", "read")(c))
+		assert.True(t, HasAllPermissions(mockRBAC, read, write)(c))
+		assert.True(t, HasAllPermissions(mockRBAC, read, write)(c))
+		assert.True(t, HasPermission(mockRBAC, "orders", "write")(c))
+		mockRBAC.AssertExpectations(t)
+	})
+}
+
+func TestCachedRBAC(t *testing.T) {
+	t.Run("should cache decisions across calls", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(true, nil).Once()
+		mockRBAC.On("HasUserPermission", "user123", "posts", "edit").Return(false, nil).Once()
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute)
+
+		for i := 0; i < 3; i++ {
+			allowed, err := cached.HasPermission("user123", "posts", "edit")
+			require.NoError(t, err)
+			assert.True(t, allowed)
+
+			allowed, err = cached.HasUserPermission("user123", "posts", "edit")
+			require.NoError(t, err)
+			assert.False(t, allowed)
+		}
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should expire decisions after the TTL", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasRolePermission", "user123", "posts", "edit").Return(true, nil).Twice()
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), 20*time.Millisecond)
+
+		cached.HasRolePermission("user123", "posts", "edit")
+		cached.HasRolePermission("user123", "posts", "edit")
+		time.Sleep(40 * time.Millisecond)
+		cached.HasRolePermission("user123", "posts", "edit")
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should invalidate on changes made through the wrapper", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(false, nil).Once()
+		mockRBAC.On("HasPermission", "user456", "posts", "edit").Return(false, nil).Once()
+		mockRBAC.On("AssignRole", "user123", "editor").Return(nil)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(true, nil).Once()
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute)
+
+		allowed, _ := cached.HasPermission("user123", "posts", "edit")
+		assert.False(t, allowed)
+		cached.HasPermission("user456", "posts", "edit")
+
+		require.NoError(t, cached.AssignRole("user123", "editor"))
+
+		allowed, _ = cached.HasPermission("user123", "posts", "edit")
+		assert.True(t, allowed)
+		allowed, _ = cached.HasPermission("user456", "posts", "edit")
+		assert.False(t, allowed)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should invalidate every user on role changes", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(false, nil).Twice()
+		mockRBAC.On("AddRolePermission", "editor", "posts", "edit").Return(nil)
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute)
+
+		cached.HasPermission("user123", "posts", "edit")
+		require.NoError(t, cached.AddRolePermission("editor", "posts", "edit"))
+		cached.HasPermission("user123", "posts", "edit")
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should invalidate from an external source", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(false, nil).Times(3)
+
+		var notify func(userID string)
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute,
+			WithInvalidationSource(func(invalidate func(userID string)) {
+				notify = invalidate
+			}))
+		require.NotNil(t, notify)
+
+		cached.HasPermission("user123", "posts", "edit")
+		notify("user123")
+		cached.HasPermission("user123", "posts", "edit")
+		notify("")
+		cached.HasPermission("user123", "posts", "edit")
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should query only uncached permissions in a set", func(t *testing.T) {
+		read := rbac.Permission{Resource: "orders", Action: "read"}
+		write := rbac.Permission{Resource: "orders", Action: "write"}
+
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "orders", "read").Return(true, nil).Once()
+		mockRBAC.On("CheckMultiplePermissions", "user123", []rbac.Permission{write}).
+			Return(map[rbac.Permission]bool{write: false}, nil).Once()
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute)
+
+		cached.HasPermission("user123", "orders", "read")
+		results, err := cached.CheckMultiplePermissions("user123", []rbac.Permission{read, write})
+		require.NoError(t, err)
+		assert.Equal(t, map[rbac.Permission]bool{read: true, write: false}, results)
+
+		results, err = cached.CheckMultiplePermissions("user123", []rbac.Permission{read, write})
+		require.NoError(t, err)
+		assert.Equal(t, map[rbac.Permission]bool{read: true, write: false}, results)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "edit").Return(false, errors.New("database error")).Twice()
+
+		cached := NewCachedRBAC(mockRBAC, newTestDecisionCache(), time.Minute)
+
+		_, err := cached.HasPermission("user123", "posts", "edit")
+		assert.Error(t, err)
+		_, err = cached.HasPermission("user123", "posts", "edit")
+		assert.Error(t, err)
+		mockRBAC.AssertExpectations(t)
+	})
+}
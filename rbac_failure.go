@@ -0,0 +1,174 @@
+package ginx
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// RBAC Failure Policy - Behaviour when the RBAC Backend Fails
+// ============================================================================
+
+// RBACFailureConfig RBAC failure policy configuration
+type RBACFailureConfig struct {
+	Retries    int           // Extra attempts after a failed check, default 0
+	Backoff    time.Duration // Delay before the first retry, doubled for each further retry, default 50ms
+	RetryAfter time.Duration // Retry-After sent with the 503 response, default 5s
+	FailOpen   []string      // Resources allowed while the backend fails; a trailing "*" matches a prefix
+	Logger     *slog.Logger  // Receives check failures, default slog.Default()
+}
+
+// WithRBACRetries retries failed permission checks with exponential backoff
+func WithRBACRetries(retries int, backoff time.Duration) Option[RBACFailureConfig] {
+	return func(c *RBACFailureConfig) {
+		c.Retries = retries
+		c.Backoff = backoff
+	}
+}
+
+// WithRBACRetryAfter sets the Retry-After of the fail-closed 503 response
+func WithRBACRetryAfter(d time.Duration) Option[RBACFailureConfig] {
+	return func(c *RBACFailureConfig) {
+		c.RetryAfter = d
+	}
+}
+
+// WithFailOpen lists low-risk resources that are allowed while the RBAC backend fails
+func WithFailOpen(resources ...string) Option[RBACFailureConfig] {
+	return func(c *RBACFailureConfig) {
+		c.FailOpen = append(c.FailOpen, resources...)
+	}
+}
+
+// WithRBACLogger sets the logger receiving permission check failures
+func WithRBACLogger(log *slog.Logger) Option[RBACFailureConfig] {
+	return func(c *RBACFailureConfig) {
+		c.Logger = log
+	}
+}
+
+// RBACFailurePolicy configures how the RBAC middlewares and conditions running after it
+// behave when the rbac.Service returns an error. Failed checks are retried as configured;
+// if they still fail, resources listed by WithFailOpen are allowed and everything else is
+// answered with 503 and Retry-After (fail-closed). Without this middleware a failed check
+// answers 500 and conditions evaluate to false.
+// Failures are always added via c.Error; with a policy they are also logged.
+func RBACFailurePolicy(options ...Option[RBACFailureConfig]) Middleware {
+	config := &RBACFailureConfig{
+		Backoff:    50 * time.Millisecond,
+		RetryAfter: 5 * time.Second,
+		Logger:     slog.Default(),
+	}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(string(rbacFailureKey), config)
+			next(c)
+		}
+	}
+}
+
+// rbacFailurePolicy returns the failure policy installed in the context, or nil
+func rbacFailurePolicy(c *gin.Context) *RBACFailureConfig {
+	value, exists := c.Get(string(rbacFailureKey))
+	if !exists {
+		return nil
+	}
+	config, _ := value.(*RBACFailureConfig)
+	return config
+}
+
+// withRBACRetries calls check, retrying per the installed failure policy.
+// Retries stop early when the request context is done.
+func withRBACRetries[T any](c *gin.Context, check func() (T, error)) (T, error) {
+	result, err := check()
+	config := rbacFailurePolicy(c)
+	if err == nil || config == nil {
+		return result, err
+	}
+
+	backoff := config.Backoff
+	for attempt := 0; attempt < config.Retries; attempt++ {
+		if !sleepContext(c.Request.Context(), backoff) {
+			break
+		}
+		if result, err = check(); err == nil {
+			return result, nil
+		}
+		backoff *= 2
+	}
+	return result, err
+}
+
+// sleepContext waits for d unless ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportPermissionFailure makes a failed check visible and reports whether the
+// failure policy allows the resources anyway (fail-open)
+func reportPermissionFailure(c *gin.Context, err error, resources ...string) bool {
+	c.Error(err)
+	config := rbacFailurePolicy(c)
+	if config == nil {
+		return false
+	}
+
+	failOpen := len(resources) > 0
+	for _, resource := range resources {
+		if !config.failsOpen(resource) {
+			failOpen = false
+			break
+		}
+	}
+
+	config.Logger.Error("RBAC permission check failed",
+		"error", err,
+		"resources", resources,
+		"fail_open", failOpen,
+		"path", c.Request.URL.Path,
+	)
+	return failOpen
+}
+
+// abortPermissionFailure answers a failed check: 503 with Retry-After under a failure
+// policy, 500 otherwise
+func abortPermissionFailure(c *gin.Context) {
+	config := rbacFailurePolicy(c)
+	if config == nil {
+		c.AbortWithStatusJSON(500, gin.H{"error": "permission check failed"})
+		return
+	}
+	seconds := int((config.RetryAfter + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(503, gin.H{"error": "permission service unavailable"})
+}
+
+// failsOpen reports whether the resource is listed as fail-open
+func (c *RBACFailureConfig) failsOpen(resource string) bool {
+	for _, pattern := range c.FailOpen {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(resource, prefix) {
+				return true
+			}
+		} else if pattern == resource {
+			return true
+		}
+	}
+	return false
+}
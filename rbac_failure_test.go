@@ -0,0 +1,128 @@
+package ginx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBACFailurePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	backendDown := errors.New("connection refused")
+
+	run := func(policy Middleware, mw Middleware) (*gin.Context, *httptest.ResponseRecorder) {
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+		chain := NewChain()
+		if policy != nil {
+			chain.Use(policy)
+		}
+		chain.Use(mw).Build()(c)
+		return c, w
+	}
+
+	t.Run("should answer 500 and record the error without a policy", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(false, backendDown)
+
+		c, result := run(nil, RequirePermission(mockRBAC, "posts", "read"))
+
+		assert.Equal(t, http.StatusInternalServerError, result.Code)
+		require.Len(t, c.Errors, 1)
+		assert.ErrorIs(t, c.Errors[0].Err, backendDown)
+	})
+
+	t.Run("should fail closed with 503 and Retry-After", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(false, backendDown)
+		var logs bytes.Buffer
+
+		c, result := run(
+			RBACFailurePolicy(WithRBACRetryAfter(30*time.Second), WithRBACLogger(slog.New(slog.NewJSONHandler(&logs, nil)))),
+			RequirePermission(mockRBAC, "posts", "read"),
+		)
+
+		assert.Equal(t, http.StatusServiceUnavailable, result.Code)
+		assert.Equal(t, "30", result.Header().Get("Retry-After"))
+		assert.Len(t, c.Errors, 1)
+		assert.Contains(t, logs.String(), "connection refused")
+		assert.Contains(t, logs.String(), `"fail_open":false`)
+	})
+
+	t.Run("should fail open for listed resources", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "public:docs", "read").Return(false, backendDown)
+		mockRBAC.On("HasPermission", "user123", "billing", "read").Return(false, backendDown)
+		policy := RBACFailurePolicy(WithFailOpen("public:*"), WithRBACLogger(slog.New(slog.DiscardHandler)))
+
+		_, result := run(policy, RequirePermission(mockRBAC, "public:docs", "read"))
+		assert.Equal(t, http.StatusOK, result.Code)
+
+		_, result = run(policy, RequirePermission(mockRBAC, "billing", "read"))
+		assert.Equal(t, http.StatusServiceUnavailable, result.Code)
+	})
+
+	t.Run("should retry with backoff", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(false, backendDown).Twice()
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(true, nil).Once()
+
+		start := time.Now()
+		c, result := run(RBACFailurePolicy(WithRBACRetries(2, 5*time.Millisecond)), RequirePermission(mockRBAC, "posts", "read"))
+
+		assert.Equal(t, http.StatusOK, result.Code)
+		assert.Empty(t, c.Errors)
+		assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should give up after bounded retries", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "posts", "read").Return(false, backendDown).Times(3)
+
+		_, result := run(
+			RBACFailurePolicy(WithRBACRetries(2, time.Millisecond), WithRBACLogger(slog.New(slog.DiscardHandler))),
+			RequirePermission(mockRBAC, "posts", "read"),
+		)
+
+		assert.Equal(t, http.StatusServiceUnavailable, result.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should apply to permission sets", func(t *testing.T) {
+		perms := []rbac.Permission{{Resource: "public:docs", Action: "read"}, {Resource: "public:faq", Action: "read"}}
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("CheckMultiplePermissions", "user123", perms).Return(nil, backendDown)
+
+		_, result := run(
+			RBACFailurePolicy(WithFailOpen("public:*"), WithRBACLogger(slog.New(slog.DiscardHandler))),
+			RequireAllPermissions(mockRBAC, perms...),
+		)
+
+		assert.Equal(t, http.StatusOK, result.Code)
+	})
+
+	t.Run("conditions should report failures and honour fail-open", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "public:docs", "read").Return(false, backendDown)
+		mockRBAC.On("HasPermission", "user123", "billing", "read").Return(false, backendDown)
+
+		c, _ := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+		RBACFailurePolicy(WithFailOpen("public:docs"), WithRBACLogger(slog.New(slog.DiscardHandler)))(func(c *gin.Context) {})(c)
+
+		assert.True(t, HasPermission(mockRBAC, "public:docs", "read")(c))
+		assert.False(t, HasPermission(mockRBAC, "billing", "read")(c))
+		assert.Len(t, c.Errors, 2)
+	})
+}
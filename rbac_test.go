@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -613,3 +614,261 @@ func TestRBACMiddlewareIntegration(t *testing.T) {
 		})
 	*/
 }
+
+func TestRequireAllPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	permissions := []rbac.Permission{
+		{Resource: "orders", Action: "read"},
+		{Resource: "customers", Action: "read"},
+	}
+
+	t.Run("should allow access when all permissions are granted", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(map[rbac.Permission]bool{
+			permissions[0]: true,
+			permissions[1]: true,
+		}, nil)
+
+		handler := RequireAllPermissions(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 listing missing permissions", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(map[rbac.Permission]bool{
+			permissions[0]: true,
+			permissions[1]: false,
+		}, nil)
+
+		handler := RequireAllPermissions(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "permission denied", response["error"])
+		assert.Equal(t, []interface{}{"customers:read"}, response["missing"])
+
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should return 500 when permission check fails", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(nil, errors.New("database error"))
+
+		handler := RequireAllPermissions(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 when user not authenticated", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+
+		handler := RequireAllPermissions(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should panic on an empty permission set", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+
+		assert.Panics(t, func() { RequireAllPermissions(mockRBAC) })
+		assert.Panics(t, func() { RequireAnyPermission(mockRBAC) })
+		assert.Panics(t, func() { HasAllPermissions(mockRBAC) })
+		assert.Panics(t, func() { HasAnyPermission(mockRBAC) })
+	})
+}
+
+func TestRequireAnyPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	permissions := []rbac.Permission{
+		{Resource: "admin", Action: "*"},
+		{Resource: "orders", Action: "own"},
+	}
+
+	t.Run("should allow access when one permission is granted", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(map[rbac.Permission]bool{
+			permissions[0]: false,
+			permissions[1]: true,
+		}, nil)
+
+		handler := RequireAnyPermission(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 when no permission is granted", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		c, w := TestContext("GET", "/test", nil)
+		SetUserID(c, "user123")
+
+		mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(map[rbac.Permission]bool{}, nil)
+
+		handler := RequireAnyPermission(mockRBAC, permissions...)(func(c *gin.Context) {
+			c.JSON(200, gin.H{"success": true})
+		})
+
+		handler(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, []interface{}{"admin:*", "orders:own"}, response["missing"])
+
+		mockRBAC.AssertExpectations(t)
+	})
+}
+
+func TestHasPermissionSets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	permissions := []rbac.Permission{
+		{Resource: "orders", Action: "read"},
+		{Resource: "customers", Action: "read"},
+	}
+
+	mockRBAC := new(MockRBACService)
+	mockRBAC.On("CheckMultiplePermissions", "user123", permissions).Return(map[rbac.Permission]bool{
+		permissions[0]: true,
+	}, nil)
+
+	c, _ := TestContext("GET", "/test", nil)
+	SetUserID(c, "user123")
+
+	assert.False(t, HasAllPermissions(mockRBAC, permissions...)(c))
+	assert.True(t, HasAnyPermission(mockRBAC, permissions...)(c))
+
+	anonymous, _ := TestContext("GET", "/test", nil)
+	assert.False(t, HasAnyPermission(mockRBAC, permissions...)(anonymous))
+}
+
+func TestRequirePermissionFunc(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(service rbac.Service) *gin.Engine {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			SetUserID(c, "user123")
+			c.Next()
+		})
+		handler := NewChain().
+			Use(RequirePermissionFunc(service, ParamPermission("project:", "id"))).
+			Build()
+		r.Any("/projects/:id", handler, func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+
+	t.Run("should check resource and action derived from the request", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "project:42", "update").Return(true, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockRBAC).ServeHTTP(w, httptest.NewRequest("PATCH", "/projects/42", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should return 403 when permission is denied", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "project:42", "delete").Return(false, nil)
+
+		w := httptest.NewRecorder()
+		newRouter(mockRBAC).ServeHTTP(w, httptest.NewRequest("DELETE", "/projects/42", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("should deny methods without a conventional action", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+
+		w := httptest.NewRecorder()
+		newRouter(mockRBAC).ServeHTTP(w, httptest.NewRequest("OPTIONS", "/projects/42", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockRBAC.AssertNotCalled(t, "HasPermission", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return 500 when permission check fails", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "project:42", "read").Return(false, errors.New("database error"))
+
+		w := httptest.NewRecorder()
+		newRouter(mockRBAC).ServeHTTP(w, httptest.NewRequest("GET", "/projects/42", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		mockRBAC.AssertExpectations(t)
+	})
+
+	t.Run("HasPermissionFunc condition", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "report:q3", "read").Return(true, nil)
+
+		c, _ := TestContext("GET", "/reports/q3", nil)
+		SetUserID(c, "user123")
+		fn := func(c *gin.Context) (string, string) { return "report:q3", MethodAction(c.Request.Method) }
+
+		assert.True(t, HasPermissionFunc(mockRBAC, fn)(c))
+		mockRBAC.AssertExpectations(t)
+	})
+}
+
+func TestMethodAction(t *testing.T) {
+	testCases := map[string]string{
+		"GET":     "read",
+		"HEAD":    "read",
+		"POST":    "create",
+		"PUT":     "update",
+		"PATCH":   "update",
+		"DELETE":  "delete",
+		"OPTIONS": "",
+	}
+	for method, action := range testCases {
+		assert.Equal(t, action, MethodAction(method), method)
+	}
+}
@@ -58,6 +58,9 @@ func RecoveryWith(handler RecoveryHandler, loggerOptions ...logger.Option) Middl
 						if rid, ok := GetRequestID(c); ok && rid != "" {
 							fields = append(fields, "request_id", rid)
 						}
+						if tc, ok := GetTraceContext(c); ok {
+							fields = append(fields, "trace_id", tc.TraceID, "span_id", tc.SpanID)
+						}
 						log.Warn("Connection broken", fields...)
 						// Write response is not possible when the connection is broken, so just abort
 						if e, ok := err.(error); ok {
@@ -78,7 +81,11 @@ func RecoveryWith(handler RecoveryHandler, loggerOptions ...logger.Option) Middl
 						if rid, ok := GetRequestID(c); ok && rid != "" {
 							fields = append(fields, "request_id", rid)
 						}
+						if tc, ok := GetTraceContext(c); ok {
+							fields = append(fields, "trace_id", tc.TraceID, "span_id", tc.SpanID)
+						}
 						log.Error("Panic recovered", fields...)
+						c.Set(string(panicKey), err)
 						// Call recovery handler
 						handler(c, err)
 					}
@@ -91,6 +98,11 @@ func RecoveryWith(handler RecoveryHandler, loggerOptions ...logger.Option) Middl
 	}
 }
 
+// recoveredPanic returns the panic value recovered by Recovery for this request
+func recoveredPanic(c *gin.Context) (any, bool) {
+	return c.Get(string(panicKey))
+}
+
 // getStack retrieves the current stack trace information.
 func getStack() string {
 	var buf [4096]byte
@@ -1,9 +1,10 @@
 package ginx
 
 import (
-	"crypto/rand"
 	"encoding/hex"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +13,29 @@ import (
 // RequestIDConfig holds configuration for the RequestID middleware
 type RequestIDConfig struct {
 	// Header is the request/response header name to carry the ID
-	// Common choices: "X-Request-ID" (default) or "Traceparent" in W3C Trace Context
+	// Common choices: "X-Request-ID" (default) or "X-Correlation-ID"
+	// For W3C Trace Context ("traceparent") use TraceContext instead
 	Header string
 
 	// Generator generates a new ID when the incoming request doesn't have one
+	// Built-in: UUIDv4, UUIDv7, ULID, KSUID and NewSnowflakeGenerator
 	Generator func() string
 
 	// RespectIncoming controls whether to trust and reuse the incoming header value
 	// If false, always override with a new ID
 	RespectIncoming bool
+
+	// Validator accepts incoming IDs; rejected IDs are replaced with a new one
+	// Default: at most 128 visible ASCII characters (no spaces or control characters)
+	Validator func(id string) bool
+
+	// InvalidLogger logs replaced incoming IDs when set (the rejected value is truncated and quoted)
+	InvalidLogger *slog.Logger
+
+	// TraceContext enables W3C Trace Context: a valid incoming traceparent is continued
+	// with a new span ID for this hop (tracestate is kept), otherwise a new trace is started.
+	// Requests without an incoming ID use the trace ID as request ID.
+	TraceContext bool
 }
 
 // RequestID options
@@ -41,16 +56,34 @@ func WithIgnoreIncoming() RequestIDOption {
 	return func(c *RequestIDConfig) { c.RespectIncoming = false }
 }
 
+// WithRequestIDValidator sets the validator for incoming IDs, see MaxLengthValidator,
+// CharsetValidator, UUIDValidator, ULIDValidator and AllValidators
+func WithRequestIDValidator(validate func(id string) bool) RequestIDOption {
+	return func(c *RequestIDConfig) { c.Validator = validate }
+}
+
+// WithInvalidRequestIDLogger logs incoming IDs rejected by the validator
+func WithInvalidRequestIDLogger(log *slog.Logger) RequestIDOption {
+	return func(c *RequestIDConfig) { c.InvalidLogger = log }
+}
+
+// WithTraceContext enables W3C Trace Context handling (traceparent/tracestate)
+func WithTraceContext() RequestIDOption {
+	return func(c *RequestIDConfig) { c.TraceContext = true }
+}
+
 // RequestID provides a simple request ID middleware.
 // Behavior:
-// - Read ID from Header (default: X-Request-ID) if present and RespectIncoming=true
-// - Otherwise generate a new ID using crypto/rand (16 bytes -> 32 hex chars)
+// - Read ID from Header (default: X-Request-ID) if present, RespectIncoming=true and accepted by Validator
+// - Otherwise generate a new ID with Generator (default: crypto/rand, 16 bytes -> 32 hex chars)
 // - Store into gin context via SetRequestID and echo back in response header
+// - With WithTraceContext, also continue or start a W3C trace (see GetTraceID, GetSpanID)
 func RequestID(opts ...RequestIDOption) Middleware {
 	cfg := RequestIDConfig{
 		Header:          "X-Request-ID",
 		Generator:       defaultRequestID,
 		RespectIncoming: true,
+		Validator:       defaultRequestIDValidator,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
@@ -64,17 +97,45 @@ func RequestID(opts ...RequestIDOption) Middleware {
 	if cfg.Generator == nil {
 		cfg.Generator = defaultRequestID
 	}
+	if cfg.Validator == nil {
+		cfg.Validator = defaultRequestIDValidator
+	}
 
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(c *gin.Context) {
+			var trace TraceContext
+			if cfg.TraceContext {
+				// Reuse the trace context of an earlier Tracing middleware
+				var ok bool
+				if trace, ok = GetTraceContext(c); !ok {
+					trace = startTraceContext(c)
+					traceContextKey.Set(c, trace)
+				}
+			}
+
 			id := ""
 			if cfg.RespectIncoming {
 				id = strings.TrimSpace(c.GetHeader(cfg.Header))
 			}
+			rejected := ""
+			if id != "" && !cfg.Validator(id) {
+				rejected, id = id, ""
+			}
+			if id == "" && cfg.TraceContext {
+				id = trace.TraceID
+			}
 			if id == "" {
 				id = cfg.Generator()
 			}
 
+			if rejected != "" && cfg.InvalidLogger != nil {
+				cfg.InvalidLogger.Warn("Invalid request ID replaced",
+					"rejected", quoteTruncated(rejected, 64),
+					"request_id", id,
+					"path", c.Request.URL.Path,
+				)
+			}
+
 			// Set into context and response header early so downstream can use it
 			SetRequestID(c, id)
 			c.Writer.Header().Set(cfg.Header, id)
@@ -96,13 +157,112 @@ func RequestID(opts ...RequestIDOption) Middleware {
 
 func defaultRequestID() string {
 	var b [16]byte
-	if _, err := rand.Read(b[:]); err != nil {
-		// Fallback: use non-crypto random from request address/time is avoided to reduce deps; return short static when entropy fails
-		return "00000000000000000000000000000000"
-	}
+	randomBytes(b[:])
 	return hex.EncodeToString(b[:])
 }
 
+// ============================================================================
+// Request ID Validators
+// ============================================================================
+
+// defaultRequestIDValidator accepts up to 128 visible ASCII characters
+var defaultRequestIDValidator = AllValidators(MaxLengthValidator(128), visibleASCII)
+
+// MaxLengthValidator accepts IDs of at most n bytes
+func MaxLengthValidator(n int) func(id string) bool {
+	return func(id string) bool {
+		return len(id) <= n
+	}
+}
+
+// CharsetValidator accepts IDs made only of the given characters, e.g.
+// "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+func CharsetValidator(chars string) func(id string) bool {
+	return func(id string) bool {
+		for _, r := range id {
+			if !strings.ContainsRune(chars, r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// UUIDValidator accepts canonical UUIDs (8-4-4-4-12 hex digits, any case)
+func UUIDValidator(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if id[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(id[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ULIDValidator accepts ULIDs (26 Crockford base32 characters, any case)
+func ULIDValidator(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false // the first character above 7 would overflow 128 bits
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if b >= 'A' && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		if strings.IndexByte(crockfordAlphabet, b) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// AllValidators accepts IDs accepted by every validator
+func AllValidators(validators ...func(id string) bool) func(id string) bool {
+	return func(id string) bool {
+		for _, validate := range validators {
+			if !validate(id) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// crockfordAlphabet is the lowercase Crockford base32 alphabet used by ULIDs
+const crockfordAlphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// visibleASCII accepts printable ASCII without spaces
+func visibleASCII(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// isHexDigit reports whether b is a hex digit of any case
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b|0x20 >= 'a' && b|0x20 <= 'f')
+}
+
+// quoteTruncated quotes s for logging, cutting it to n bytes
+func quoteTruncated(s string, n int) string {
+	if len(s) > n {
+		return strconv.Quote(s[:n]) + "..."
+	}
+	return strconv.Quote(s)
+}
+
 // headerValueContains checks if a comma-separated header list contains a value (case-sensitive, simple check)
 func headerValueContains(list, value string) bool {
 	// Cheap parse without allocations for most small lists
@@ -1,8 +1,11 @@
 package ginx
 
 import (
+	"bytes"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 
@@ -224,3 +227,172 @@ func TestRequestID_Concurrency(t *testing.T) {
 		t.Errorf("concurrency error: %s", e)
 	}
 }
+
+func TestRequestID_TraceContext(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parent  = "00f067aa0ba902b7"
+	)
+	m := RequestID(WithTraceContext())
+	next := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	t.Run("continues a valid traceparent with a new span", func(t *testing.T) {
+		c, w := TestContext("GET", "/api/test", map[string]string{
+			"traceparent": "00-" + traceID + "-" + parent + "-01",
+			"tracestate":  "congo=t61rcWkgMzE",
+		})
+		m(next)(c)
+
+		tc, ok := GetTraceContext(c)
+		if !ok {
+			t.Fatal("trace context should be set")
+		}
+		if tc.TraceID != traceID || tc.ParentID != parent || tc.Flags != "01" || tc.State != "congo=t61rcWkgMzE" {
+			t.Errorf("unexpected trace context %+v", tc)
+		}
+		if spanID, _ := GetSpanID(c); spanID == parent || len(spanID) != 16 {
+			t.Errorf("expected a new 16-hex span id, got %q", spanID)
+		}
+		if w.Header().Get("X-Request-ID") != traceID {
+			t.Errorf("request id should default to the trace id, got %q", w.Header().Get("X-Request-ID"))
+		}
+		if got, _ := TraceContextFromContext(c.Request.Context()); got != tc {
+			t.Error("trace context should be mirrored into the request context")
+		}
+	})
+
+	t.Run("starts a new trace for invalid headers", func(t *testing.T) {
+		invalid := []string{
+			"",
+			"00-" + traceID + "-" + parent, // too short
+			"00-00000000000000000000000000000000-" + parent + "-01", // zero trace id
+			"00-" + traceID + "-0000000000000000-01",                // zero parent id
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + parent + "-01", // uppercase
+			"ff-" + traceID + "-" + parent + "-01",                  // forbidden version
+			"00-" + traceID + "-" + parent + "-01-extra",            // version 00 with extra fields
+			"00_" + traceID + "_" + parent + "_01",                  // wrong separators
+		}
+		for _, header := range invalid {
+			c, _ := TestContext("GET", "/api/test", map[string]string{
+				"traceparent": header,
+				"tracestate":  "congo=t61rcWkgMzE",
+			})
+			m(next)(c)
+
+			tc, _ := GetTraceContext(c)
+			if tc.TraceID == traceID || !isHex32(tc.TraceID) || tc.ParentID != "" || tc.State != "" {
+				t.Errorf("header %q: expected a new trace, got %+v", header, tc)
+			}
+		}
+	})
+
+	t.Run("accepts future versions with extra fields", func(t *testing.T) {
+		c, _ := TestContext("GET", "/api/test", map[string]string{
+			"traceparent": "01-" + traceID + "-" + parent + "-01-future",
+		})
+		m(next)(c)
+
+		if id, _ := GetTraceID(c); id != traceID {
+			t.Errorf("expected trace id %q, got %q", traceID, id)
+		}
+	})
+
+	t.Run("keeps an incoming request id", func(t *testing.T) {
+		c, _ := TestContext("GET", "/api/test", map[string]string{"X-Request-ID": "req-1"})
+		m(next)(c)
+
+		if id, _ := GetRequestID(c); id != "req-1" {
+			t.Errorf("expected incoming request id, got %q", id)
+		}
+		if _, ok := GetTraceID(c); !ok {
+			t.Error("trace id should be set")
+		}
+	})
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		c, _ := TestContext("GET", "/api/test", map[string]string{
+			"traceparent": "00-" + traceID + "-" + parent + "-01",
+		})
+		RequestID()(next)(c)
+
+		if _, ok := GetTraceID(c); ok {
+			t.Error("trace context should not be set without WithTraceContext")
+		}
+	})
+}
+
+func TestRequestID_Validation(t *testing.T) {
+	next := func(c *gin.Context) { c.Status(http.StatusOK) }
+	run := func(incoming string, opts ...RequestIDOption) string {
+		c, _ := TestContext("GET", "/api/test", map[string]string{"X-Request-ID": incoming})
+		RequestID(opts...)(next)(c)
+		id, _ := GetRequestID(c)
+		return id
+	}
+
+	t.Run("default validator rejects oversized and unprintable IDs", func(t *testing.T) {
+		for _, incoming := range []string{strings.Repeat("a", 129), "req\x1bone", "req one", "réq"} {
+			if id := run(incoming); id == incoming || !isHex32(id) {
+				t.Errorf("incoming %q should be replaced, got %q", incoming, id)
+			}
+		}
+		if id := run("corr-abc_1.2:3"); id != "corr-abc_1.2:3" {
+			t.Errorf("valid incoming id should be kept, got %q", id)
+		}
+	})
+
+	t.Run("custom validators", func(t *testing.T) {
+		uuid := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+		ulid := "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+		cases := []struct {
+			validator func(string) bool
+			incoming  string
+			valid     bool
+		}{
+			{UUIDValidator, uuid, true},
+			{UUIDValidator, strings.ToUpper(uuid), true},
+			{UUIDValidator, "f47ac10b58cc4372a5670e02b2c3d479", false},
+			{UUIDValidator, "g47ac10b-58cc-4372-a567-0e02b2c3d479", false},
+			{ULIDValidator, ulid, true},
+			{ULIDValidator, strings.ToLower(ulid), true},
+			{ULIDValidator, "01ARZ3NDEKTSV4RRFFQ69G5FAI", false},
+			{ULIDValidator, "81ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+			{ULIDValidator, strings.Repeat("\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19", 3)[:26], false},
+			{ULIDValidator, ulid[:25] + "\x19", false},
+			{ULIDValidator, ulid[:24] + "é", false},
+			{ULIDValidator, ulid[:25] + "\xd6", false},
+			{ULIDValidator, ulid[:25] + "@", false},
+			{CharsetValidator("abc123"), "cab321", true},
+			{CharsetValidator("abc123"), "cab-321", false},
+			{AllValidators(MaxLengthValidator(4), CharsetValidator("abc")), "abca", true},
+			{AllValidators(MaxLengthValidator(4), CharsetValidator("abc")), "abcab", false},
+		}
+		for _, tc := range cases {
+			if got := tc.validator(tc.incoming); got != tc.valid {
+				t.Errorf("validator(%q) = %v, want %v", tc.incoming, got, tc.valid)
+			}
+		}
+
+		if id := run("not-a-uuid", WithRequestIDValidator(UUIDValidator)); id == "not-a-uuid" {
+			t.Error("invalid incoming id should be replaced")
+		}
+		if id := run(uuid, WithRequestIDValidator(UUIDValidator)); id != uuid {
+			t.Errorf("valid uuid should be kept, got %q", id)
+		}
+	})
+
+	t.Run("logs replaced IDs when configured", func(t *testing.T) {
+		var logs bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&logs, nil))
+
+		id := run("evil\nlevel=ERROR msg=forged", WithInvalidRequestIDLogger(log))
+
+		out := logs.String()
+		if !strings.Contains(out, "Invalid request ID replaced") || !strings.Contains(out, id) {
+			t.Errorf("expected replacement to be logged, got %q", out)
+		}
+		if strings.Count(out, "\n") != 1 {
+			t.Errorf("rejected value should not break the log line, got %q", out)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package ginx
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Token Roles - Role Checks without an RBAC Service
+// ============================================================================
+
+// RoleHierarchy maps a role to the roles it inherits, for example
+//
+//	ginx.RoleHierarchy{"admin": {"editor"}, "editor": {"viewer"}}
+//
+// grants "editor" and "viewer" to every "admin". Inheritance is transitive and cycle-safe.
+// The package-level role functions use no hierarchy; its methods apply it.
+type RoleHierarchy map[string][]string
+
+// Expand returns the roles together with every role they inherit
+func (h RoleHierarchy) Expand(roles []string) []string {
+	expanded := make([]string, 0, len(roles))
+	queue := slices.Clone(roles)
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if slices.Contains(expanded, role) {
+			continue
+		}
+		expanded = append(expanded, role)
+		queue = append(queue, h[role]...)
+	}
+	return expanded
+}
+
+// RequireRoles requires the context roles to include all of the given roles
+func RequireRoles(roles ...string) Middleware {
+	return RoleHierarchy(nil).RequireRoles(roles...)
+}
+
+// RequireAnyRole requires the context roles to include at least one of the given roles
+func RequireAnyRole(roles ...string) Middleware {
+	return RoleHierarchy(nil).RequireAnyRole(roles...)
+}
+
+// HasRole checks if the context roles include the given role
+func HasRole(role string) Condition {
+	return RoleHierarchy(nil).HasRole(role)
+}
+
+// HasAnyRole checks if the context roles include at least one of the given roles
+func HasAnyRole(roles ...string) Condition {
+	return RoleHierarchy(nil).HasAnyRole(roles...)
+}
+
+// RequireRoles is like the package-level RequireRoles, with inherited roles granted
+func (h RoleHierarchy) RequireRoles(roles ...string) Middleware {
+	return h.requireRoles(roles, true)
+}
+
+// RequireAnyRole is like the package-level RequireAnyRole, with inherited roles granted
+func (h RoleHierarchy) RequireAnyRole(roles ...string) Middleware {
+	return h.requireRoles(roles, false)
+}
+
+// HasRole is like the package-level HasRole, with inherited roles granted
+func (h RoleHierarchy) HasRole(role string) Condition {
+	return h.HasAnyRole(role)
+}
+
+// HasAnyRole is like the package-level HasAnyRole, with inherited roles granted
+func (h RoleHierarchy) HasAnyRole(roles ...string) Condition {
+	return func(c *gin.Context) bool {
+		granted, _ := GetUserRoles(c)
+		missing := missingRoles(h.Expand(granted), roles)
+		return len(missing) < len(roles)
+	}
+}
+
+// requireRoles provides the internal role middleware implementation
+func (h RoleHierarchy) requireRoles(roles []string, all bool) Middleware {
+	if len(roles) == 0 {
+		panic("role configuration error: empty role list")
+	}
+	resource, action := "role:"+strings.Join(roles, " "), "any"
+	if all {
+		action = "all"
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if _, exists := GetUserID(c); !exists {
+				auditDecision(c, resource, action, AuditDeny, "user not authenticated")
+				abortAuthFailure(c, 401, gin.H{"error": "user not authenticated"})
+				return
+			}
+
+			// An authenticated user without roles holds none
+			granted, _ := GetUserRoles(c)
+			missing := missingRoles(h.Expand(granted), roles)
+			if all && len(missing) > 0 {
+				auditDecision(c, resource, action, AuditDeny, "missing roles "+strings.Join(missing, " "))
+				abortAuthFailure(c, 403, gin.H{"error": "insufficient roles", "missing": missing})
+				return
+			}
+			if !all && len(missing) == len(roles) {
+				auditDecision(c, resource, action, AuditDeny, "insufficient roles")
+				abortAuthFailure(c, 403, gin.H{"error": "insufficient roles"})
+				return
+			}
+
+			auditDecision(c, resource, action, AuditAllow, "")
+			next(c)
+		}
+	}
+}
+
+// missingRoles returns the required roles not present in granted
+func missingRoles(granted, required []string) []string {
+	missing := make([]string, 0, len(required))
+	for _, role := range required {
+		if !slices.Contains(granted, role) {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,132 @@
+package ginx
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleHierarchyExpand(t *testing.T) {
+	hierarchy := RoleHierarchy{
+		"admin":  {"editor", "auditor"},
+		"editor": {"viewer"},
+		"viewer": {"editor"}, // cycle
+	}
+
+	assert.Equal(t, []string{"admin", "editor", "auditor", "viewer"}, hierarchy.Expand([]string{"admin"}))
+	assert.Equal(t, []string{"viewer", "editor"}, hierarchy.Expand([]string{"viewer"}))
+	assert.Equal(t, []string{"guest"}, hierarchy.Expand([]string{"guest"}))
+	assert.Empty(t, hierarchy.Expand(nil))
+}
+
+func TestRequireRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(mw Middleware, roles []string) (int, map[string]interface{}) {
+		c, w := TestContext("GET", "/test", nil)
+		if roles != nil {
+			SetUserID(c, "user1")
+			SetUserRoles(c, roles)
+		}
+		mw(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	t.Run("should allow when all roles are present", func(t *testing.T) {
+		code, _ := run(RequireRoles("editor", "billing"), []string{"billing", "editor"})
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("should return 403 listing missing roles", func(t *testing.T) {
+		code, response := run(RequireRoles("editor", "billing"), []string{"editor"})
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Equal(t, "insufficient roles", response["error"])
+		assert.Equal(t, []interface{}{"billing"}, response["missing"])
+	})
+
+	t.Run("should return 401 without user in context", func(t *testing.T) {
+		code, response := run(RequireRoles("editor"), nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, "user not authenticated", response["error"])
+	})
+
+	t.Run("should return 403 for a user without roles", func(t *testing.T) {
+		for _, mw := range []Middleware{RequireRoles("editor"), RequireAnyRole("editor")} {
+			c, w := TestContext("GET", "/test", nil)
+			SetUserID(c, "user1")
+
+			mw(func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})(c)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+		}
+	})
+
+	t.Run("should return 403 after BasicAuth without roles", func(t *testing.T) {
+		c, w := TestContext("GET", "/test", nil)
+		c.Request.SetBasicAuth("admin", "s3cret")
+
+		BasicAuth(func(u, p string) bool { return true })(RequireAnyRole("admin")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		}))(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should panic on an empty role list", func(t *testing.T) {
+		assert.Panics(t, func() { RequireRoles() })
+		assert.Panics(t, func() { RequireAnyRole() })
+		assert.Panics(t, func() { RoleHierarchy{}.RequireRoles() })
+	})
+
+	t.Run("RequireAnyRole should allow with one matching role", func(t *testing.T) {
+		code, _ := run(RequireAnyRole("admin", "editor"), []string{"editor"})
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("RequireAnyRole should reject without matching role", func(t *testing.T) {
+		code, _ := run(RequireAnyRole("admin", "editor"), []string{"viewer"})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("should grant inherited roles", func(t *testing.T) {
+		hierarchy := RoleHierarchy{"admin": {"editor"}, "editor": {"viewer"}}
+
+		code, _ := run(hierarchy.RequireRoles("viewer", "editor"), []string{"admin"})
+		assert.Equal(t, http.StatusOK, code)
+
+		code, _ = run(hierarchy.RequireAnyRole("admin"), []string{"editor"})
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = run(RequireRoles("viewer"), []string{"admin"})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+}
+
+func TestHasRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := TestContext("GET", "/test", nil)
+	SetUserRoles(c, []string{"editor"})
+
+	assert.True(t, HasRole("editor")(c))
+	assert.False(t, HasRole("admin")(c))
+	assert.True(t, HasAnyRole("admin", "editor")(c))
+	assert.False(t, HasAnyRole("admin", "viewer")(c))
+
+	hierarchy := RoleHierarchy{"editor": {"viewer"}}
+	assert.True(t, hierarchy.HasRole("viewer")(c))
+	assert.True(t, hierarchy.HasAnyRole("admin", "viewer")(c))
+
+	anonymous, _ := TestContext("GET", "/test", nil)
+	assert.False(t, HasRole("editor")(anonymous))
+}
@@ -0,0 +1,130 @@
+package ginx
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// OAuth2 Scopes
+// ============================================================================
+
+// scopeClaims lists the claim names scopes are read from, in order.
+// "scope" is the RFC 8693/9068 space-delimited string; "scp" and "scopes" are common array forms.
+var scopeClaims = []string{"scope", "scp", "scopes"}
+
+// GetScopes returns the OAuth2 scopes of the validated token.
+// Both space-delimited string and array claim formats are supported.
+func GetScopes(c *gin.Context) ([]string, bool) {
+	claims, ok := GetClaims(c)
+	if !ok {
+		return nil, false
+	}
+	for _, name := range scopeClaims {
+		value, exists := claims[name]
+		if !exists {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			return strings.Fields(v), true
+		case []any:
+			scopes := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+			return scopes, true
+		}
+	}
+	return nil, false
+}
+
+// ============================================================================
+// Middleware - Scope Authorization
+// ============================================================================
+
+// RequireScopes requires the token to carry all of the given scopes.
+// A missing scope is answered with 403 insufficient_scope and the RFC 6750 challenge.
+func RequireScopes(scopes ...string) Middleware {
+	return requireScopes(scopes, hasAllScopes)
+}
+
+// RequireAnyScope requires the token to carry at least one of the given scopes.
+func RequireAnyScope(scopes ...string) Middleware {
+	return requireScopes(scopes, hasAnyScope)
+}
+
+// requireScopes provides the internal scope middleware implementation
+func requireScopes(required []string, match func(granted, required []string) bool) Middleware {
+	if len(required) == 0 {
+		panic("scope configuration error: empty scope list")
+	}
+	scope := strings.Join(required, " ")
+	challenge := `Bearer error="insufficient_scope", scope="` + scope + `"`
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if _, ok := GetToken(c); !ok {
+				auditDecision(c, "scope:"+scope, "", AuditDeny, "missing token")
+				c.Header("WWW-Authenticate", "Bearer")
+				abortAuthFailure(c, 401, gin.H{"error": "missing token"})
+				return
+			}
+
+			granted, _ := GetScopes(c)
+			if !match(granted, required) {
+				auditDecision(c, "scope:"+scope, "", AuditDeny, "insufficient_scope")
+				c.Header("WWW-Authenticate", challenge)
+				abortAuthFailure(c, 403, gin.H{
+					"error": "insufficient_scope",
+					"scope": scope,
+				})
+				return
+			}
+
+			auditDecision(c, "scope:"+scope, "", AuditAllow, "")
+			next(c)
+		}
+	}
+}
+
+// ============================================================================
+// Conditions - Scope Authorization
+// ============================================================================
+
+// HasScopes checks if the token carries all of the given scopes
+func HasScopes(scopes ...string) Condition {
+	return func(c *gin.Context) bool {
+		granted, ok := GetScopes(c)
+		return ok && hasAllScopes(granted, scopes)
+	}
+}
+
+// HasAnyScope checks if the token carries at least one of the given scopes
+func HasAnyScope(scopes ...string) Condition {
+	return func(c *gin.Context) bool {
+		granted, ok := GetScopes(c)
+		return ok && hasAnyScope(granted, scopes)
+	}
+}
+
+// hasAllScopes checks if granted contains every required scope
+func hasAllScopes(granted, required []string) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAnyScope checks if granted contains at least one required scope
+func hasAnyScope(granted, required []string) bool {
+	return slices.ContainsFunc(required, func(scope string) bool {
+		return slices.Contains(granted, scope)
+	})
+}
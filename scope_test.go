@@ -0,0 +1,146 @@
+package ginx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name   string
+		claims map[string]any
+		want   []string
+		ok     bool
+	}{
+		{"space-delimited scope", map[string]any{"scope": "orders:read  orders:write"}, []string{"orders:read", "orders:write"}, true},
+		{"scp array", map[string]any{"scp": []string{"orders:read", "profile"}}, []string{"orders:read", "profile"}, true},
+		{"scopes array", map[string]any{"scopes": []string{"profile"}}, []string{"profile"}, true},
+		{"scp string", map[string]any{"scp": "profile email"}, []string{"profile", "email"}, true},
+		{"no scope claim", map[string]any{"user_id": "user123"}, nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := TestContext("GET", "/test", nil)
+			SetToken(c, &jwt.Token{UserID: "user123", Raw: rawTestToken(t, tc.claims)})
+
+			scopes, ok := GetScopes(c)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, scopes)
+		})
+	}
+
+	t.Run("without token", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+
+		_, ok := GetScopes(c)
+		assert.False(t, ok)
+	})
+}
+
+func TestRequireScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(t *testing.T, claims map[string]any) (*gin.Context, *httptest.ResponseRecorder) {
+		c, w := TestContext("GET", "/orders", nil)
+		SetToken(c, &jwt.Token{UserID: "client1", Raw: rawTestToken(t, claims)})
+		return c, w
+	}
+
+	t.Run("should allow when all scopes are granted", func(t *testing.T) {
+		c, w := newContext(t, map[string]any{"scope": "orders:read orders:write"})
+
+		RequireScopes("orders:read", "orders:write")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return 403 insufficient_scope when a scope is missing", func(t *testing.T) {
+		c, w := newContext(t, map[string]any{"scp": []string{"orders:read"}})
+
+		RequireScopes("orders:read", "orders:write")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="orders:read orders:write"`, w.Header().Get("WWW-Authenticate"))
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "insufficient_scope", response["error"])
+		assert.Equal(t, "orders:read orders:write", response["scope"])
+	})
+
+	t.Run("should return 403 when token has no scope claim", func(t *testing.T) {
+		c, w := newContext(t, map[string]any{"user_id": "client1"})
+
+		RequireScopes("orders:read")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should return 401 without token", func(t *testing.T) {
+		c, w := TestContext("GET", "/orders", nil)
+
+		RequireScopes("orders:read")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("RequireAnyScope should allow with one matching scope", func(t *testing.T) {
+		c, w := newContext(t, map[string]any{"scope": "orders:admin"})
+
+		RequireAnyScope("orders:read", "orders:admin")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("RequireAnyScope should reject without matching scope", func(t *testing.T) {
+		c, w := newContext(t, map[string]any{"scope": "profile"})
+
+		RequireAnyScope("orders:read", "orders:admin")(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should panic on an empty scope list", func(t *testing.T) {
+		assert.Panics(t, func() { RequireScopes() })
+		assert.Panics(t, func() { RequireAnyScope() })
+	})
+}
+
+func TestScopeConditions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, _ := TestContext("GET", "/orders", nil)
+	SetToken(c, &jwt.Token{UserID: "client1", Raw: rawTestToken(t, map[string]any{"scope": "orders:read profile"})})
+
+	assert.True(t, HasScopes("orders:read")(c))
+	assert.True(t, HasScopes("orders:read", "profile")(c))
+	assert.False(t, HasScopes("orders:read", "orders:write")(c))
+	assert.True(t, HasAnyScope("orders:write", "profile")(c))
+	assert.False(t, HasAnyScope("orders:write")(c))
+
+	anonymous, _ := TestContext("GET", "/orders", nil)
+	assert.False(t, HasScopes()(anonymous))
+	assert.False(t, HasAnyScope("profile")(anonymous))
+}
@@ -0,0 +1,183 @@
+package ginx
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// Multi-tenancy - Tenant Resolution
+// ============================================================================
+
+// TenantResolver resolves the tenant ID of a request.
+// It returns false when the request does not identify a tenant by its means.
+type TenantResolver func(c *gin.Context) (string, bool)
+
+// TenantConfig tenant resolution configuration
+type TenantConfig struct {
+	Resolvers []TenantResolver     // Tried in order, the first match wins
+	Optional  bool                 // Continue without tenant instead of answering 400
+	Validate  func(id string) bool // Rejects unknown tenants with 404, optional
+	Format    func(id string) bool // Rejects malformed tenant IDs with 400, default defaultTenantIDFormat
+}
+
+// defaultTenantIDFormat accepts up to 64 letters, digits, '-', '_' and '.', so client-supplied
+// IDs cannot forge the "tenant:<id>|" cache and rate limit keys or inject into logs
+var defaultTenantIDFormat = AllValidators(MaxLengthValidator(64),
+	CharsetValidator("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_."))
+
+// WithTenantResolver adds a tenant resolver (may be repeated)
+func WithTenantResolver(resolver TenantResolver) Option[TenantConfig] {
+	return func(c *TenantConfig) {
+		c.Resolvers = append(c.Resolvers, resolver)
+	}
+}
+
+// WithOptionalTenant lets requests without a resolvable tenant through
+func WithOptionalTenant() Option[TenantConfig] {
+	return func(c *TenantConfig) {
+		c.Optional = true
+	}
+}
+
+// WithTenantFormat replaces the tenant ID format check, see MaxLengthValidator,
+// CharsetValidator and AllValidators
+func WithTenantFormat(validate func(id string) bool) Option[TenantConfig] {
+	return func(c *TenantConfig) {
+		c.Format = validate
+	}
+}
+
+// WithTenantValidator sets a function rejecting unknown tenant IDs
+func WithTenantValidator(validate func(id string) bool) Option[TenantConfig] {
+	return func(c *TenantConfig) {
+		c.Validate = validate
+	}
+}
+
+// Tenant resolves the tenant of the request and stores it via SetTenantID.
+// Requests without tenant are answered with 400 unless WithOptionalTenant is set,
+// malformed tenant IDs with 400 and tenants rejected by the validator with 404.
+func Tenant(options ...Option[TenantConfig]) Middleware {
+	config := &TenantConfig{Format: defaultTenantIDFormat}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			tenantID, ok := resolveTenant(c, config.Resolvers)
+			if !ok {
+				if config.Optional {
+					next(c)
+					return
+				}
+				c.AbortWithStatusJSON(400, gin.H{"error": "tenant required"})
+				return
+			}
+
+			if config.Format != nil && !config.Format(tenantID) {
+				c.AbortWithStatusJSON(400, gin.H{"error": "invalid tenant"})
+				return
+			}
+			if config.Validate != nil && !config.Validate(tenantID) {
+				c.AbortWithStatusJSON(404, gin.H{"error": "unknown tenant"})
+				return
+			}
+
+			SetTenantID(c, tenantID)
+			next(c)
+		}
+	}
+}
+
+// resolveTenant tries the resolvers in order
+func resolveTenant(c *gin.Context, resolvers []TenantResolver) (string, bool) {
+	for _, resolve := range resolvers {
+		if id, ok := resolve(c); ok && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// ============================================================================
+// Built-in Tenant Resolvers
+// ============================================================================
+
+// TenantFromSubdomain resolves the tenant from the first label of a host under
+// baseDomain, e.g. "acme" for "acme.example.com" with baseDomain "example.com"
+func TenantFromSubdomain(baseDomain string) TenantResolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	return func(c *gin.Context) (string, bool) {
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(strings.ToLower(host), suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return "", false
+		}
+		return sub, true
+	}
+}
+
+// TenantFromHeader resolves the tenant from a request header, e.g. "X-Tenant-ID"
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *gin.Context) (string, bool) {
+		id := strings.TrimSpace(c.GetHeader(name))
+		return id, id != ""
+	}
+}
+
+// TenantFromParam resolves the tenant from a route parameter, e.g. "tenant" for "/t/:tenant/..."
+func TenantFromParam(name string) TenantResolver {
+	return func(c *gin.Context) (string, bool) {
+		id := c.Param(name)
+		return id, id != ""
+	}
+}
+
+// TenantFromClaim resolves the tenant from a string claim of the validated JWT
+func TenantFromClaim(name string) TenantResolver {
+	return func(c *gin.Context) (string, bool) {
+		id, ok := GetClaim[string](c, name)
+		return id, ok && id != ""
+	}
+}
+
+// ============================================================================
+// Tenant-scoped Helpers
+// ============================================================================
+
+// TenantPermission builds a PermissionFunc checking resource within the request's tenant,
+// as "<tenant>:<resource>". Requests without tenant are denied.
+//
+//	ginx.RequirePermissionFunc(rbacService, ginx.TenantPermission("orders", "read"))
+func TenantPermission(resource, action string) PermissionFunc {
+	return func(c *gin.Context) (string, string) {
+		tenantID, ok := GetTenantID(c)
+		if !ok || tenantID == "" {
+			return "", ""
+		}
+		return tenantID + ":" + resource, action
+	}
+}
+
+// IsTenant checks if the request belongs to one of the given tenants
+func IsTenant(tenantIDs ...string) Condition {
+	return func(c *gin.Context) bool {
+		tenantID, ok := GetTenantID(c)
+		if !ok {
+			return false
+		}
+		for _, id := range tenantIDs {
+			if id == tenantID {
+				return true
+			}
+		}
+		return false
+	}
+}
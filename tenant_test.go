@@ -0,0 +1,186 @@
+package ginx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("subdomain", func(t *testing.T) {
+		resolve := TenantFromSubdomain("example.com")
+		cases := map[string]string{
+			"acme.example.com":      "acme",
+			"ACME.example.com:8080": "acme",
+			"example.com":           "",
+			"a.b.example.com":       "",
+			"acme.other.com":        "",
+		}
+		for host, expected := range cases {
+			c, _ := TestContext("GET", "/", nil)
+			c.Request.Host = host
+			id, ok := resolve(c)
+			assert.Equal(t, expected, id, host)
+			assert.Equal(t, expected != "", ok, host)
+		}
+	})
+
+	t.Run("header", func(t *testing.T) {
+		c, _ := TestContext("GET", "/", map[string]string{"X-Tenant-ID": " acme "})
+		id, ok := TenantFromHeader("X-Tenant-ID")(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", id)
+
+		c, _ = TestContext("GET", "/", nil)
+		_, ok = TenantFromHeader("X-Tenant-ID")(c)
+		assert.False(t, ok)
+	})
+
+	t.Run("param", func(t *testing.T) {
+		c, _ := TestContext("GET", "/t/acme/orders", nil)
+		c.Params = gin.Params{{Key: "tenant", Value: "acme"}}
+		id, ok := TenantFromParam("tenant")(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", id)
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		c, _ := TestContext("GET", "/", nil)
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: rawTestToken(t, map[string]any{"tenant_id": "acme"})})
+		id, ok := TenantFromClaim("tenant_id")(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", id)
+
+		_, ok = TenantFromClaim("org")(c)
+		assert.False(t, ok)
+	})
+}
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(headers map[string]string, options ...Option[TenantConfig]) (*gin.Context, *httptest.ResponseRecorder) {
+		c, w := TestContext("GET", "/orders", headers)
+		NewChain().Use(Tenant(options...)).Build()(c)
+		return c, w
+	}
+	byHeader := WithTenantResolver(TenantFromHeader("X-Tenant-ID"))
+
+	t.Run("should store the first resolved tenant", func(t *testing.T) {
+		c, w := run(map[string]string{"X-Tenant-ID": "acme", "X-Org": "globex"},
+			WithTenantResolver(TenantFromHeader("X-Missing")),
+			byHeader,
+			WithTenantResolver(TenantFromHeader("X-Org")),
+		)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		id, ok := GetTenantID(c)
+		assert.True(t, ok)
+		assert.Equal(t, "acme", id)
+	})
+
+	t.Run("should reject requests without tenant", func(t *testing.T) {
+		_, w := run(nil, byHeader)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"tenant required"}`, w.Body.String())
+	})
+
+	t.Run("should continue without tenant when optional", func(t *testing.T) {
+		c, w := run(nil, byHeader, WithOptionalTenant())
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, ok := GetTenantID(c)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject unknown tenants", func(t *testing.T) {
+		known := WithTenantValidator(func(id string) bool { return id == "acme" })
+
+		_, w := run(map[string]string{"X-Tenant-ID": "globex"}, byHeader, known)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		_, w = run(map[string]string{"X-Tenant-ID": "acme"}, byHeader, known)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject malformed tenant IDs", func(t *testing.T) {
+		for _, id := range []string{"acme|GET|/admin", "ac\x01me", "acmé", strings.Repeat("a", 65)} {
+			c, w := run(map[string]string{"X-Tenant-ID": id}, byHeader)
+			assert.Equal(t, http.StatusBadRequest, w.Code, id)
+			assert.JSONEq(t, `{"error":"invalid tenant"}`, w.Body.String())
+			_, ok := GetTenantID(c)
+			assert.False(t, ok)
+		}
+	})
+
+	t.Run("should accept a custom tenant ID format", func(t *testing.T) {
+		_, w := run(map[string]string{"X-Tenant-ID": "acme:eu"}, byHeader,
+			WithTenantFormat(CharsetValidator("abcdefghijklmnopqrstuvwxyz:")))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTenantAwareFeatures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("rate limit keys by tenant", func(t *testing.T) {
+		store := NewMemoryLimiterStore(time.Minute)
+		defer store.Close()
+		middleware := RateLimit(1, 1, WithStore(store), WithTenant())
+		handler := middleware(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		request := func(tenantID, ip string) int {
+			c, w := TestContext("GET", "/test", map[string]string{"X-Forwarded-For": ip})
+			SetTenantID(c, tenantID)
+			handler(c)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusOK, request("acme", "10.0.0.1"))
+		assert.Equal(t, http.StatusTooManyRequests, request("acme", "10.0.0.2"))
+		assert.Equal(t, http.StatusOK, request("globex", "10.0.0.1"))
+	})
+
+	t.Run("cache keys are scoped to the tenant", func(t *testing.T) {
+		c, _ := TestContext("GET", "/api/users?page=1", nil)
+		assert.Equal(t, "GET|/api/users?page=1", generateCacheKey(c))
+
+		SetTenantID(c, "acme")
+		assert.Equal(t, "tenant:acme|GET|/api/users?page=1", generateCacheKey(c))
+	})
+
+	t.Run("permissions are checked within the tenant", func(t *testing.T) {
+		mockRBAC := new(MockRBACService)
+		mockRBAC.On("HasPermission", "user123", "acme:orders", "read").Return(true, nil)
+
+		c, w := TestContext("GET", "/orders", nil)
+		SetUserID(c, "user123")
+		SetTenantID(c, "acme")
+		RequirePermissionFunc(mockRBAC, TenantPermission("orders", "read"))(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockRBAC.AssertExpectations(t)
+
+		c, w = TestContext("GET", "/orders", nil)
+		SetUserID(c, "user123")
+		RequirePermissionFunc(mockRBAC, TenantPermission("orders", "read"))(func(c *gin.Context) {})(c)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("IsTenant condition", func(t *testing.T) {
+		c, _ := TestContext("GET", "/", nil)
+		assert.False(t, IsTenant("acme")(c))
+
+		SetTenantID(c, "acme")
+		assert.True(t, IsTenant("globex", "acme")(c))
+		assert.False(t, IsTenant("globex")(c))
+	})
+}
@@ -0,0 +1,112 @@
+package ginx
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ============================================================================
+// W3C Trace Context - traceparent / tracestate
+// ============================================================================
+
+// TraceContext is the W3C trace context of the current hop
+type TraceContext struct {
+	TraceID  string // 32 lowercase hex characters, shared by every hop of the trace
+	SpanID   string // 16 lowercase hex characters, generated for this hop
+	ParentID string // Span ID of the caller, empty when the trace starts here
+	Flags    string // 2 hex characters, "01" when sampled
+	State    string // Vendor specific tracestate, forwarded unchanged
+}
+
+// Traceparent formats the traceparent header for calls made from this hop
+func (tc TraceContext) Traceparent() string {
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + tc.Flags
+}
+
+var traceContextKey = NewContextKey[TraceContext]("ginx.trace_context")
+
+// GetTraceContext gets the trace context from the context
+func GetTraceContext(c *gin.Context) (TraceContext, bool) {
+	return traceContextKey.Get(c)
+}
+
+// GetTraceID gets the trace ID from the context
+func GetTraceID(c *gin.Context) (string, bool) {
+	tc, ok := traceContextKey.Get(c)
+	return tc.TraceID, ok
+}
+
+// GetSpanID gets the span ID of this hop from the context
+func GetSpanID(c *gin.Context) (string, bool) {
+	tc, ok := traceContextKey.Get(c)
+	return tc.SpanID, ok
+}
+
+// TraceContextFromContext gets the trace context from a request context
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	return traceContextKey.FromContext(ctx)
+}
+
+// startTraceContext continues the trace of a valid traceparent header with a new span,
+// or starts a new sampled trace when the header is missing or invalid
+func startTraceContext(c *gin.Context) TraceContext {
+	if tc, ok := parseTraceparent(c.GetHeader("traceparent")); ok {
+		tc.ParentID = tc.SpanID
+		tc.SpanID = newSpanID()
+		tc.State = strings.TrimSpace(strings.Join(c.Request.Header.Values("tracestate"), ","))
+		return tc
+	}
+	return TraceContext{TraceID: newTraceID(), SpanID: newSpanID(), Flags: "01"}
+}
+
+// parseTraceparent parses and validates a traceparent header.
+// The returned SpanID is the caller's span (the parent of this hop).
+func parseTraceparent(header string) (TraceContext, bool) {
+	header = strings.TrimSpace(header)
+	// version "-" trace-id "-" parent-id "-" flags, later versions may append fields
+	if len(header) < 55 || (len(header) > 55 && header[55] != '-') {
+		return TraceContext{}, false
+	}
+	version, traceID, spanID, flags := header[0:2], header[3:35], header[36:52], header[53:55]
+	if header[2] != '-' || header[35] != '-' || header[52] != '-' {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(header) != 55) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(traceID) || traceID == strings.Repeat("0", 32) {
+		return TraceContext{}, false
+	}
+	if !isLowerHex(spanID) || spanID == strings.Repeat("0", 16) || !isLowerHex(flags) {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceID: traceID, SpanID: spanID, Flags: flags}, true
+}
+
+// isLowerHex reports whether s only contains lowercase hex digits
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newTraceID generates a random 16 byte trace ID
+func newTraceID() string {
+	return defaultRequestID()
+}
+
+// newSpanID generates a random non-zero 8 byte span ID
+func newSpanID() string {
+	var b [8]byte
+	randomBytes(b[:])
+	if b == [8]byte{} {
+		b[7] = 1
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,151 @@
+package ginx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ============================================================================
+// Tracing - OpenTelemetry Server Spans
+// ============================================================================
+
+// tracerName is the instrumentation scope of ginx spans
+const tracerName = "github.com/simp-lee/ginx"
+
+// TracingConfig tracing configuration
+type TracingConfig struct {
+	TracerProvider trace.TracerProvider          // Default otel.GetTracerProvider()
+	Propagator     propagation.TextMapPropagator // Extracts the parent span, default otel.GetTextMapPropagator()
+}
+
+// WithTracerProvider sets the tracer provider creating the spans
+func WithTracerProvider(provider trace.TracerProvider) Option[TracingConfig] {
+	return func(c *TracingConfig) {
+		c.TracerProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator extracting the incoming trace context
+func WithPropagator(propagator propagation.TextMapPropagator) Option[TracingConfig] {
+	return func(c *TracingConfig) {
+		c.Propagator = propagator
+	}
+}
+
+// Tracing starts an OpenTelemetry server span per request, named after the route template
+// (c.FullPath(), the method for unmatched routes). The span records status and response size,
+// user, tenant and request IDs, and the RateLimit decision. 5xx responses and panics recovered
+// by Recovery mark the span as failed; timeouts are added as a "timeout" event.
+// The span context is stored in c.Request.Context() for child spans and outgoing calls, and as
+// the ginx trace context (GetTraceContext); place Tracing before RequestID(WithTraceContext()).
+func Tracing(options ...Option[TracingConfig]) Middleware {
+	config := &TracingConfig{}
+	for _, opt := range options {
+		opt(config)
+	}
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = otel.GetTextMapPropagator()
+	}
+	tracer := config.TracerProvider.Tracer(tracerName)
+
+	return func(next gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			ctx := config.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+			route := c.FullPath()
+			name := route
+			if name == "" {
+				name = c.Request.Method
+			}
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("user_agent.original", c.Request.UserAgent()),
+			}
+			if route != "" {
+				attrs = append(attrs, attribute.String("http.route", route))
+			}
+			parent := trace.SpanContextFromContext(ctx)
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			c.Request = c.Request.WithContext(ctx)
+			setSpanTraceContext(c, span.SpanContext(), parent)
+			defer func() {
+				if err := recover(); err != nil {
+					span.RecordError(fmt.Errorf("panic: %v", err), trace.WithStackTrace(true))
+					span.SetStatus(codes.Error, "panic")
+					panic(err)
+				}
+			}()
+
+			next(c)
+
+			recordSpanOutcome(c, span)
+		}
+	}
+}
+
+// setSpanTraceContext stores the span as the ginx trace context, so logs, RequestID
+// and NewTransport use the IDs of the OTel span
+func setSpanTraceContext(c *gin.Context, span, parent trace.SpanContext) {
+	if !span.IsValid() {
+		return
+	}
+	tc := TraceContext{
+		TraceID: span.TraceID().String(),
+		SpanID:  span.SpanID().String(),
+		Flags:   span.TraceFlags().String(),
+		State:   span.TraceState().String(),
+	}
+	if parent.IsValid() {
+		tc.ParentID = parent.SpanID().String()
+	}
+	traceContextKey.Set(c, tc)
+}
+
+// recordSpanOutcome adds the response and ginx request state to the span
+func recordSpanOutcome(c *gin.Context, span trace.Span) {
+	status := c.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if size := c.Writer.Size(); size >= 0 {
+		span.SetAttributes(attribute.Int("http.response.body.size", size))
+	}
+
+	if userID, ok := GetUserID(c); ok && userID != "" {
+		span.SetAttributes(attribute.String("enduser.id", userID))
+	}
+	if requestID, ok := GetRequestID(c); ok && requestID != "" {
+		span.SetAttributes(attribute.String("ginx.request_id", requestID))
+	}
+	if tenantID, ok := GetTenantID(c); ok && tenantID != "" {
+		span.SetAttributes(attribute.String("ginx.tenant_id", tenantID))
+	}
+	if limited, ok := rateLimitDecision(c); ok {
+		span.SetAttributes(attribute.Bool("ginx.rate_limited", limited))
+	}
+
+	if IsTimeout(c) || c.Request.Context().Err() == context.DeadlineExceeded {
+		span.AddEvent("timeout")
+	}
+	if recovered, ok := recoveredPanic(c); ok {
+		span.RecordError(fmt.Errorf("panic: %v", recovered))
+		span.SetStatus(codes.Error, "panic recovered")
+		return
+	}
+	if status >= 500 {
+		span.SetStatus(codes.Error, strconv.Itoa(status))
+	}
+}
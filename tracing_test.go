@@ -0,0 +1,182 @@
+package ginx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(middlewares ...Middleware) (*gin.Engine, *tracetest.InMemoryExporter) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		chain := NewChain().Use(Tracing(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{})))
+		for _, m := range middlewares {
+			chain.Use(m)
+		}
+		r := gin.New()
+		r.Use(chain.Build())
+		return r, exporter
+	}
+	attrs := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		values := make(map[attribute.Key]attribute.Value)
+		for _, kv := range span.Attributes {
+			values[kv.Key] = kv.Value
+		}
+		return values
+	}
+
+	t.Run("should create a server span named after the route", func(t *testing.T) {
+		r, exporter := setup(RequestID(), func(next gin.HandlerFunc) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				SetUserID(c, "user123")
+				next(c)
+			}
+		})
+		var handlerSpan trace.SpanContext
+		r.GET("/users/:id", func(c *gin.Context) {
+			handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+			c.String(http.StatusOK, "hello")
+		})
+
+		req := httptest.NewRequest("GET", "/users/42", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "/users/:id", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.Equal(t, span.SpanContext.SpanID(), handlerSpan.SpanID())
+		assert.Equal(t, codes.Unset, span.Status.Code)
+
+		values := attrs(span)
+		assert.Equal(t, "GET", values["http.request.method"].AsString())
+		assert.Equal(t, "/users/:id", values["http.route"].AsString())
+		assert.Equal(t, int64(200), values["http.response.status_code"].AsInt64())
+		assert.Equal(t, int64(5), values["http.response.body.size"].AsInt64())
+		assert.Equal(t, "user123", values["enduser.id"].AsString())
+		assert.NotEmpty(t, values["ginx.request_id"].AsString())
+	})
+
+	t.Run("should mark 5xx and recovered panics as errors", func(t *testing.T) {
+		r, exporter := setup(Recovery())
+		r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+		r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+		assert.Equal(t, "panic recovered", spans[1].Status.Description)
+		require.NotEmpty(t, spans[1].Events)
+		assert.Equal(t, "exception", spans[1].Events[0].Name)
+	})
+
+	t.Run("should record rate limit decisions", func(t *testing.T) {
+		store := NewMemoryLimiterStore(time.Minute)
+		defer store.Close()
+		r, exporter := setup(RateLimit(1, 1, WithStore(store)))
+		r.GET("/limited", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/limited", nil))
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/limited", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.False(t, attrs(spans[0])["ginx.rate_limited"].AsBool())
+		assert.True(t, attrs(spans[1])["ginx.rate_limited"].AsBool())
+	})
+
+	t.Run("should record timeouts as events", func(t *testing.T) {
+		r, exporter := setup(Timeout(WithTimeout(10 * time.Millisecond)))
+		r.GET("/slow", func(c *gin.Context) {
+			time.Sleep(30 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		require.NotEmpty(t, spans[0].Events)
+		assert.Equal(t, "timeout", spans[0].Events[0].Name)
+	})
+
+	t.Run("should share span IDs with the ginx trace context", func(t *testing.T) {
+		var outgoing string
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			outgoing = r.Header.Get("traceparent")
+		}))
+		defer upstream.Close()
+		client := &http.Client{Transport: NewTransport(nil)}
+
+		for name, tracingFirst := range map[string]bool{"tracing first": true, "request id first": false} {
+			t.Run(name, func(t *testing.T) {
+				exporter := tracetest.NewInMemoryExporter()
+				provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+				tracing := Tracing(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{}))
+				chain := NewChain()
+				if tracingFirst {
+					chain.Use(tracing).Use(RequestID(WithTraceContext()))
+				} else {
+					chain.Use(RequestID(WithTraceContext())).Use(tracing)
+				}
+				r := gin.New()
+				r.Use(chain.Build())
+
+				var tc TraceContext
+				r.GET("/call", func(c *gin.Context) {
+					tc, _ = GetTraceContext(c)
+					req, err := http.NewRequestWithContext(c.Request.Context(), "GET", upstream.URL, nil)
+					require.NoError(t, err)
+					resp, err := client.Do(req)
+					require.NoError(t, err)
+					resp.Body.Close()
+					c.Status(http.StatusOK)
+				})
+
+				req := httptest.NewRequest("GET", "/call", nil)
+				req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+				r.ServeHTTP(httptest.NewRecorder(), req)
+
+				spans := exporter.GetSpans()
+				require.Len(t, spans, 1)
+				span := spans[0].SpanContext
+				assert.Equal(t, span.TraceID().String(), tc.TraceID)
+				assert.Equal(t, span.SpanID().String(), tc.SpanID)
+				assert.Equal(t, "00f067aa0ba902b7", tc.ParentID)
+				assert.Equal(t, "00-"+span.TraceID().String()+"-"+span.SpanID().String()+"-01", outgoing)
+			})
+		}
+	})
+
+	t.Run("should name unmatched routes by method", func(t *testing.T) {
+		r, exporter := setup()
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/unknown", nil))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "POST", spans[0].Name)
+	})
+}
@@ -0,0 +1,115 @@
+package ginx
+
+import (
+	"net/http"
+)
+
+// ============================================================================
+// Outbound Propagation - http.RoundTripper for Service-to-Service Calls
+// ============================================================================
+
+// TransportConfig outbound propagation configuration
+type TransportConfig struct {
+	RequestIDHeader string // Header carrying the request ID, default "X-Request-ID"; "" disables
+	TenantHeader    string // Header carrying the tenant ID, disabled by default
+	Token           bool   // Forward the caller's JWT as "Authorization: Bearer", disabled by default
+	TraceContext    bool   // Forward traceparent/tracestate set by RequestID(WithTraceContext()), default true
+}
+
+// WithPropagateRequestID sets the header carrying the request ID ("" disables it)
+func WithPropagateRequestID(header string) Option[TransportConfig] {
+	return func(c *TransportConfig) {
+		c.RequestIDHeader = header
+	}
+}
+
+// WithPropagateTenant forwards the tenant ID in the given header, e.g. "X-Tenant-ID"
+func WithPropagateTenant(header string) Option[TransportConfig] {
+	return func(c *TransportConfig) {
+		c.TenantHeader = header
+	}
+}
+
+// WithPropagateTraceContext enables or disables forwarding of traceparent and tracestate
+func WithPropagateTraceContext(enabled bool) Option[TransportConfig] {
+	return func(c *TransportConfig) {
+		c.TraceContext = enabled
+	}
+}
+
+// WithPropagateToken forwards the caller's bearer token.
+// Only use it for clients talking to trusted services that accept the same tokens.
+func WithPropagateToken() Option[TransportConfig] {
+	return func(c *TransportConfig) {
+		c.Token = true
+	}
+}
+
+// NewTransport wraps base (http.DefaultTransport if nil) so outgoing requests carry the request ID
+// and trace context, and optionally the tenant and bearer token, of the context they are made with.
+// Headers already set on the outgoing request are kept.
+//
+//	client := &http.Client{Transport: ginx.NewTransport(nil, ginx.WithPropagateTenant("X-Tenant-ID"))}
+//	req, _ := http.NewRequestWithContext(c.Request.Context(), "GET", "http://orders/api/orders", nil)
+//	resp, err := client.Do(req)
+func NewTransport(base http.RoundTripper, options ...Option[TransportConfig]) http.RoundTripper {
+	config := &TransportConfig{RequestIDHeader: "X-Request-ID", TraceContext: true}
+	for _, opt := range options {
+		opt(config)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &propagatingTransport{base: base, config: config}
+}
+
+// propagatingTransport injects identity headers from the request context
+type propagatingTransport struct {
+	base   http.RoundTripper
+	config *TransportConfig
+}
+
+// RoundTrip implements http.RoundTripper without modifying the caller's request
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	headers := make(map[string]string, 5)
+
+	if t.config.RequestIDHeader != "" {
+		if id, ok := RequestIDFromContext(ctx); ok && id != "" {
+			headers[t.config.RequestIDHeader] = id
+		}
+	}
+	if t.config.TraceContext {
+		if tc, ok := TraceContextFromContext(ctx); ok {
+			headers["Traceparent"] = tc.Traceparent()
+			if tc.State != "" {
+				headers["Tracestate"] = tc.State
+			}
+		}
+	}
+	if t.config.TenantHeader != "" {
+		if id, ok := TenantIDFromContext(ctx); ok && id != "" {
+			headers[t.config.TenantHeader] = id
+		}
+	}
+	if t.config.Token {
+		if token, ok := tokenKey.FromContext(ctx); ok && token != nil && token.Raw != "" {
+			headers["Authorization"] = "Bearer " + token.Raw
+		}
+	}
+
+	var out *http.Request
+	for name, value := range headers {
+		if req.Header.Get(name) != "" {
+			continue
+		}
+		if out == nil {
+			out = req.Clone(ctx)
+		}
+		out.Header.Set(name, value)
+	}
+	if out == nil {
+		return t.base.RoundTrip(req)
+	}
+	return t.base.RoundTrip(out)
+}
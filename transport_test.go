@@ -0,0 +1,95 @@
+package ginx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/simp-lee/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	incoming := func() *gin.Context {
+		c, _ := TestContext("GET", "/test", nil)
+		SetRequestID(c, "req-1")
+		SetTenantID(c, "acme")
+		SetToken(c, &jwt.Token{UserID: "user123", Raw: "header.payload.sig"})
+		return c
+	}
+	call := func(t *testing.T, c *gin.Context, transport http.RoundTripper, header http.Header) {
+		req, err := http.NewRequestWithContext(c.Request.Context(), "GET", upstream.URL, nil)
+		require.NoError(t, err)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := (&http.Client{Transport: transport}).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	t.Run("should forward the request ID by default", func(t *testing.T) {
+		call(t, incoming(), NewTransport(nil), nil)
+
+		assert.Equal(t, "req-1", received.Get("X-Request-ID"))
+		assert.Empty(t, received.Get("X-Tenant-ID"))
+		assert.Empty(t, received.Get("Authorization"))
+	})
+
+	t.Run("should forward tenant and token when enabled", func(t *testing.T) {
+		call(t, incoming(), NewTransport(nil,
+			WithPropagateRequestID("X-Correlation-ID"),
+			WithPropagateTenant("X-Tenant-ID"),
+			WithPropagateToken(),
+		), nil)
+
+		assert.Equal(t, "req-1", received.Get("X-Correlation-ID"))
+		assert.Empty(t, received.Get("X-Request-ID"))
+		assert.Equal(t, "acme", received.Get("X-Tenant-ID"))
+		assert.Equal(t, "Bearer header.payload.sig", received.Get("Authorization"))
+	})
+
+	t.Run("should keep headers set by the caller", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Authorization", "Bearer service-token")
+		call(t, incoming(), NewTransport(nil, WithPropagateToken()), header)
+
+		assert.Equal(t, "Bearer service-token", received.Get("Authorization"))
+		assert.Equal(t, "req-1", received.Get("X-Request-ID"))
+	})
+
+	t.Run("should forward the trace context with this hop as parent", func(t *testing.T) {
+		c := incoming()
+		traceContextKey.Set(c, TraceContext{
+			TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:  "00f067aa0ba902b7",
+			Flags:   "01",
+			State:   "congo=t61rcWkgMzE",
+		})
+		call(t, c, NewTransport(nil), nil)
+
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", received.Get("Traceparent"))
+		assert.Equal(t, "congo=t61rcWkgMzE", received.Get("Tracestate"))
+
+		call(t, c, NewTransport(nil, WithPropagateTraceContext(false)), nil)
+		assert.Empty(t, received.Get("Traceparent"))
+	})
+
+	t.Run("should pass requests without identity through", func(t *testing.T) {
+		c, _ := TestContext("GET", "/test", nil)
+		call(t, c, NewTransport(nil, WithPropagateTenant("X-Tenant-ID")), nil)
+
+		assert.Empty(t, received.Get("X-Request-ID"))
+		assert.Empty(t, received.Get("X-Tenant-ID"))
+	})
+}